@@ -0,0 +1,75 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+)
+
+// QueryDataAlerts returns alert summaries, use QueryDataAlert for owner, view and recipients.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#query_data-driven_alerts
+func (api *API) QueryDataAlerts(siteId string) ([]DataAlert, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/dataAlerts", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QueryDataAlertsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.DataAlerts.DataAlerts, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#query_data-driven_alert_details
+func (api *API) QueryDataAlert(siteId string, dataAlertId string) (DataAlert, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/dataAlerts/%s", api.Server, api.Version, siteId, dataAlertId)
+	headers := make(map[string]string)
+	retval := DataAlertResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.DataAlert, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#delete_data-driven_alert
+func (api *API) DeleteDataAlert(siteId string, dataAlertId string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/dataAlerts/%s", api.Server, api.Version, siteId, dataAlertId)
+	return api.delete(url)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#add_user_to_data-driven_alert
+func (api *API) AddUserToDataAlert(siteId string, dataAlertId string, userId string) (User, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/dataAlerts/%s/users", api.Server, api.Version, siteId, dataAlertId)
+	userRequest := UserRequest{Request: User{ID: userId}}
+	headers := make(map[string]string)
+	payload, err := api.encode(userRequest, headers)
+	if err != nil {
+		return User{}, err
+	}
+	retval := QueryUserOnSiteResponse{}
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.User, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#delete_user_from_data-driven_alert
+func (api *API) DeleteUserFromDataAlert(siteId string, dataAlertId string, userId string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/dataAlerts/%s/users/%s", api.Server, api.Version, siteId, dataAlertId, userId)
+	return api.delete(url)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_driven_alerts.htm#update_data-driven_alert
+func (api *API) ChangeDataAlertOwner(siteId string, dataAlertId string, ownerId string) (*DataAlert, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/dataAlerts/%s", api.Server, api.Version, siteId, dataAlertId)
+	updateRequest := UpdateDataAlertRequest{Request: DataAlert{Owner: &User{ID: ownerId}}}
+	headers := make(map[string]string)
+	payload, err := api.encode(updateRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := DataAlertResponse{}
+	err = api.makeRequest(url, PUT, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.DataAlert, err
+}
@@ -13,9 +13,10 @@ package tableau4go
 
 import (
 	"bytes"
-	"encoding/xml"
-	"errors"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -27,12 +28,12 @@ const content_type_header = "Content-Type"
 const content_length_header = "Content-Length"
 const auth_header = "X-Tableau-Auth"
 const application_xml_content_type = "application/xml"
+const application_json_content_type = "application/json"
 const POST = "POST"
 const GET = "GET"
+const PUT = "PUT"
 const DELETE = "DELETE"
 
-var ErrDoesNotExist = errors.New("Does Not Exist")
-
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Sign_In%3FTocPath%3DAPI%2520Reference%7C_____51
 func (api *API) Signin(username, password string, contentUrl string, userIdToImpersonate string) error {
 	url := fmt.Sprintf("%s/api/%s/auth/signin", api.Server, api.Version)
@@ -40,6 +41,23 @@ func (api *API) Signin(username, password string, contentUrl string, userIdToImp
 	if len(userIdToImpersonate) > 0 {
 		credentials.Impersonate = &User{ID: userIdToImpersonate}
 	}
+	credentials.Site = &Site{ContentUrl: api.siteContentUrl(contentUrl)}
+	request := SigninRequest{Request: credentials}
+	headers := make(map[string]string)
+	payload, err := api.encode(request, headers)
+	if err != nil {
+		return err
+	}
+	retval := AuthResponse{}
+	// a repeated sign in only hands out another token, so it is safe to retry
+	err = api.makeSafeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout, true)
+	if err == nil {
+		api.setCredentials(retval.Credentials)
+	}
+	return err
+}
+
+func (api *API) siteContentUrl(contentUrl string) string {
 	siteName := contentUrl
 	// this seems to have changed. If you are looking for the default site, you must pass
 	// blank
@@ -48,19 +66,24 @@ func (api *API) Signin(username, password string, contentUrl string, userIdToImp
 			siteName = ""
 		}
 	}
-	credentials.Site = &Site{ContentUrl: siteName}
-	request := SigninRequest{Request: credentials}
-	signInXML, err := request.XML()
+	return siteName
+}
+
+// SwitchSite trades the current auth token for one scoped to the site with contentUrl,
+// updating AuthToken and SiteID. Not available for Tableau Online.
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_authentication.htm#switch_site
+func (api *API) SwitchSite(contentUrl string) error {
+	url := fmt.Sprintf("%s/api/%s/auth/switchSite", api.Server, api.Version)
+	request := SwitchSiteRequest{Request: Site{ContentUrl: api.siteContentUrl(contentUrl)}}
+	headers := make(map[string]string)
+	payload, err := api.encode(request, headers)
 	if err != nil {
 		return err
 	}
-	payload := string(signInXML)
-	headers := make(map[string]string)
-	headers[content_type_header] = application_xml_content_type
 	retval := AuthResponse{}
-	err = api.makeRequest(url, POST, []byte(payload), &retval, headers, connectTimeOut, readWriteTimeout)
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
 	if err == nil {
-		api.AuthToken = retval.Credentials.Token
+		api.setCredentials(retval.Credentials)
 	}
 	return err
 }
@@ -69,8 +92,11 @@ func (api *API) Signin(username, password string, contentUrl string, userIdToImp
 func (api *API) Signout() error {
 	url := fmt.Sprintf("%s/api/%s/auth/signout", api.Server, api.Version)
 	headers := make(map[string]string)
-	headers[content_type_header] = application_xml_content_type
+	headers[content_type_header] = api.mediaType()
 	err := api.makeRequest(url, POST, nil, nil, headers, connectTimeOut, readWriteTimeout)
+	if err == nil {
+		api.clearSession()
+	}
 	return err
 }
 
@@ -129,6 +155,33 @@ func (api *API) querySite(url string) (Site, error) {
 	return retval.Site, err
 }
 
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#create_site
+func (api *API) CreateSite(site Site) (*Site, error) {
+	url := fmt.Sprintf("%s/api/%s/sites", api.Server, api.Version)
+	return api.sendSite(url, POST, site)
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_site.htm#update_site
+func (api *API) UpdateSite(siteId string, site Site) (*Site, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s", api.Server, api.Version, siteId)
+	// the id is in the url and usage is read only, the server rejects either in the body
+	site.ID = ""
+	site.Usage = nil
+	return api.sendSite(url, PUT, site)
+}
+
+func (api *API) sendSite(url string, method string, site Site) (*Site, error) {
+	siteRequest := SiteRequest{Request: site}
+	headers := make(map[string]string)
+	payload, err := api.encode(siteRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := QuerySiteResponse{}
+	err = api.makeRequest(url, method, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Site, err
+}
+
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_User_On_Site%3FTocPath%3DAPI%2520Reference%7C_____47
 func (api *API) QueryUserOnSite(siteId, userId string) (User, error) {
 	url := fmt.Sprintf("%s/api/%s/sites/%s/users/%s", api.Server, api.Version, siteId, userId)
@@ -157,7 +210,7 @@ func (api *API) GetProjectByName(siteId, name string) (Project, error) {
 			return project, nil
 		}
 	}
-	return Project{}, fmt.Errorf("Project Named '%s' Not Found", name)
+	return Project{}, fmt.Errorf("Project Named '%s' Not Found: %w", name, ErrDoesNotExist)
 }
 
 func (api *API) GetProjectByID(siteId, ID string) (Project, error) {
@@ -170,7 +223,7 @@ func (api *API) GetProjectByID(siteId, ID string) (Project, error) {
 			return project, nil
 		}
 	}
-	return Project{}, fmt.Errorf("Project with ID '%s' Not Found", ID)
+	return Project{}, fmt.Errorf("Project with ID '%s' Not Found: %w", ID, ErrDoesNotExist)
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Query_Datasources%3FTocPath%3DAPI%2520Reference%7C_____33
@@ -195,14 +248,13 @@ func (api *API) GetSiteID(siteName string) (string, error) {
 func (api *API) CreateProject(siteId string, project Project) (*Project, error) {
 	url := fmt.Sprintf("%s/api/%s/sites/%s/projects", api.Server, api.Version, siteId)
 	createProjectRequest := CreateProjectRequest{Request: project}
-	xmlRep, err := createProjectRequest.XML()
+	headers := make(map[string]string)
+	payload, err := api.encode(createProjectRequest, headers)
 	if err != nil {
 		return nil, err
 	}
-	headers := make(map[string]string)
-	headers[content_type_header] = application_xml_content_type
 	createProjectResponse := CreateProjectResponse{}
-	err = api.makeRequest(url, POST, xmlRep, &createProjectResponse, headers, connectTimeOut, readWriteTimeout)
+	err = api.makeRequest(url, POST, payload, &createProjectResponse, headers, connectTimeOut, readWriteTimeout)
 	return &createProjectResponse.Project, err
 }
 
@@ -214,26 +266,62 @@ func (api *API) PublishTDS(siteId string, tdsMetadata Datasource, fullTds string
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Publish_Datasource%3FTocPath%3DAPI%2520Reference%7C_____31
 func (api *API) publishDatasource(siteId string, tdsMetadata Datasource, datasource string, datasourceType string, overwrite bool) (retval *Datasource, err error) {
 	url := fmt.Sprintf("%s/api/%s/sites/%s/datasources?datasourceType=%s&overwrite=%v", api.Server, api.Version, siteId, datasourceType, overwrite)
-	payload := fmt.Sprintf("--%s\r\n", api.Boundary)
-	payload += "Content-Disposition: name=\"request_payload\"\r\n"
-	payload += "Content-Type: text/xml\r\n"
-	payload += "\r\n"
 	tdsRequest := DatasourceCreateRequest{Request: tdsMetadata}
-	xmlRepresentation, err := tdsRequest.XML()
+	requestPayload, err := api.encode(tdsRequest, make(map[string]string))
 	if err != nil {
 		return retval, err
 	}
-	payload += string(xmlRepresentation)
-	payload += fmt.Sprintf("\r\n--%s\r\n", api.Boundary)
-	payload += fmt.Sprintf("Content-Disposition: name=\"tableau_datasource\"; filename=\"%s.tds\"\r\n", tdsMetadata.Name)
-	payload += "Content-Type: application/octet-stream\r\n"
-	payload += "\r\n"
-	payload += datasource
-	payload += fmt.Sprintf("\r\n--%s--\r\n", api.Boundary)
+	payload := api.multipartPayload(requestPayload, "tableau_datasource", fmt.Sprintf("%s.%s", tdsMetadata.Name, datasourceType), []byte(datasource))
 	headers := make(map[string]string)
 	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", api.Boundary)
-	err = api.makeRequest(url, POST, []byte(payload), retval, headers, connectTimeOut, readWriteTimeout)
-	return retval, err
+	publishResponse := UpdateDatasourceResponse{}
+	err = api.makeRequest(url, POST, payload, &publishResponse, headers, connectTimeOut, readWriteTimeout)
+	return &publishResponse.Datasource, err
+}
+
+// multipartPayload builds the multipart/mixed body the publish calls expect: the
+// request_payload part, in api's format, followed by the file itself.
+func (api *API) multipartPayload(requestPayload []byte, fileFieldName string, fileName string, content []byte) []byte {
+	var payload bytes.Buffer
+	payload.WriteString(fmt.Sprintf("--%s\r\n", api.Boundary))
+	payload.WriteString("Content-Disposition: name=\"request_payload\"\r\n")
+	if api.jsonFormat() {
+		payload.WriteString("Content-Type: application/json\r\n")
+	} else {
+		payload.WriteString("Content-Type: text/xml\r\n")
+	}
+	payload.WriteString("\r\n")
+	payload.Write(requestPayload)
+	payload.WriteString(fmt.Sprintf("\r\n--%s\r\n", api.Boundary))
+	payload.WriteString(fmt.Sprintf("Content-Disposition: name=\"%s\"; filename=\"%s\"\r\n", fileFieldName, fileName))
+	payload.WriteString("Content-Type: application/octet-stream\r\n")
+	payload.WriteString("\r\n")
+	payload.Write(content)
+	payload.WriteString(fmt.Sprintf("\r\n--%s--\r\n", api.Boundary))
+	return payload.Bytes()
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source
+func (api *API) CertifyDatasource(siteId string, datasourceId string, certificationNote string) (*Datasource, error) {
+	return api.updateDatasourceCertification(siteId, datasourceId, DatasourceCertification{IsCertified: true, CertificationNote: certificationNote})
+}
+
+//https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#update_data_source
+func (api *API) UncertifyDatasource(siteId string, datasourceId string) (*Datasource, error) {
+	return api.updateDatasourceCertification(siteId, datasourceId, DatasourceCertification{IsCertified: false})
+}
+
+func (api *API) updateDatasourceCertification(siteId string, datasourceId string, certification DatasourceCertification) (*Datasource, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/datasources/%s", api.Server, api.Version, siteId, datasourceId)
+	updateRequest := UpdateDatasourceCertificationRequest{Request: certification}
+	headers := make(map[string]string)
+	payload, err := api.encode(updateRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	updateResponse := UpdateDatasourceResponse{}
+	err = api.makeRequest(url, PUT, payload, &updateResponse, headers, connectTimeOut, readWriteTimeout)
+	return &updateResponse.Datasource, err
 }
 
 //http://onlinehelp.tableau.com/current/api/rest_api/en-us/help.htm#REST/rest_api_ref.htm#Delete_Datasource%3FTocPath%3DAPI%2520Reference%7C_____15
@@ -277,70 +365,120 @@ func (api *API) delete(url string) error {
 
 func (api *API) makeRequest(requestUrl string, method string, payload []byte, result interface{}, headers map[string]string,
 	cTimeout time.Duration, rwTimeout time.Duration) error {
-	var debug = false
-	if debug {
-		fmt.Printf("%s:%v\n", method, requestUrl)
-		if payload != nil {
-			fmt.Printf("%v\n", string(payload))
-		}
+	return api.makeSafeRequest(requestUrl, method, payload, result, headers, cTimeout, rwTimeout, false)
+}
+
+// makeSafeRequest is makeRequest for calls that may be retried whatever their method.
+func (api *API) makeSafeRequest(requestUrl string, method string, payload []byte, result interface{}, headers map[string]string,
+	cTimeout time.Duration, rwTimeout time.Duration, safe bool) error {
+	ctx, end := api.startCall(method, requestUrl)
+	statusCode, err := api.sendAndDecode(ctx, requestUrl, method, payload, result, headers, safe)
+	end(statusCode, err)
+	return err
+}
+
+func (api *API) sendAndDecode(ctx context.Context, requestUrl string, method string, payload []byte, result interface{}, headers map[string]string, safe bool) (int, error) {
+	if api.jsonFormat() {
+		headers[accept_header] = application_json_content_type
 	}
-	client := DefaultTimeoutClient()
-	var req *http.Request
-	if len(payload) > 0 {
-		var httpErr error
-		req, httpErr = http.NewRequest(strings.TrimSpace(method), strings.TrimSpace(requestUrl), bytes.NewBuffer(payload))
-		if httpErr != nil {
-			return httpErr
-		}
-		req.Header.Add(content_length_header, strconv.Itoa(len(payload)))
-	} else {
-		var httpErr error
-		req, httpErr = http.NewRequest(strings.TrimSpace(method), strings.TrimSpace(requestUrl), nil)
-		if httpErr != nil {
-			return httpErr
-		}
+	resp, httpErr := api.send(ctx, requestUrl, method, payload, headers, safe)
+	if httpErr != nil {
+		return 0, httpErr
+	}
+	defer resp.Body.Close()
+	body, readBodyError := ioutil.ReadAll(resp.Body)
+	api.logBody(ctx, "tableau response body", method, requestUrl, resp.Header, body)
+	if readBodyError != nil {
+		return resp.StatusCode, readBodyError
 	}
-	if headers != nil {
-		for header, headerValue := range headers {
-			req.Header.Add(header, headerValue)
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, responseError(method, requestUrl, resp.StatusCode, body)
+	}
+	if result != nil {
+		// else unmarshall to the result type specified by caller
+		err := api.decode(body, result)
+		if err != nil {
+			return resp.StatusCode, decodeError(method, requestUrl, body, err)
 		}
 	}
-	if len(api.AuthToken) > 0 {
-		if debug {
-			fmt.Printf("%s:%s\n", auth_header, api.AuthToken)
+	return resp.StatusCode, nil
+}
+
+// makeJSONRequest is used by the services that only speak JSON (Metadata API,
+// VizQL Data Service). Their error bodies differ, so the status and raw body are
+// handed back for the caller to interpret. Both only read, so calls are retry safe.
+func (api *API) makeJSONRequest(requestUrl string, method string, payload interface{}) (int, []byte, error) {
+	var jsonPayload []byte
+	if payload != nil {
+		var err error
+		jsonPayload, err = json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
 		}
-		req.Header.Add(auth_header, api.AuthToken)
 	}
-	var httpErr error
-	resp, httpErr := client.Do(req)
-	if httpErr != nil {
-		return httpErr
+	headers := make(map[string]string)
+	headers[content_type_header] = application_json_content_type
+	headers[accept_header] = application_json_content_type
+	ctx, end := api.startCall(method, requestUrl)
+	resp, err := api.send(ctx, requestUrl, method, jsonPayload, headers, true)
+	if err != nil {
+		end(0, err)
+		return 0, nil, err
 	}
 	defer resp.Body.Close()
-	body, readBodyError := ioutil.ReadAll(resp.Body)
-	if debug {
-		fmt.Printf("t4g Response:%v\n", string(body))
-	}
-	if readBodyError != nil {
-		return readBodyError
+	body, err := ioutil.ReadAll(resp.Body)
+	api.logBody(ctx, "tableau response body", method, requestUrl, resp.Header, body)
+	if err == nil && resp.StatusCode >= 300 {
+		end(resp.StatusCode, responseError(method, requestUrl, resp.StatusCode, body))
+	} else {
+		end(resp.StatusCode, err)
 	}
-	if resp.StatusCode == 404 {
-		return ErrDoesNotExist
+	return resp.StatusCode, body, err
+}
+
+// download performs a GET and streams a successful response body to w
+// rather than buffering it, for the image/pdf/csv export endpoints.
+func (api *API) download(requestUrl string, w io.Writer, headers map[string]string) error {
+	ctx, end := api.startCall(GET, requestUrl)
+	statusCode, err := api.downloadTo(ctx, requestUrl, w, headers)
+	end(statusCode, err)
+	return err
+}
+
+func (api *API) downloadTo(ctx context.Context, requestUrl string, w io.Writer, headers map[string]string) (int, error) {
+	resp, err := api.send(ctx, requestUrl, GET, nil, headers, false)
+	if err != nil {
+		return 0, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
-		tErrorResponse := ErrorResponse{}
-		err := xml.Unmarshal(body, &tErrorResponse)
+		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return err
+			return resp.StatusCode, err
 		}
-		return tErrorResponse.Error
+		return resp.StatusCode, responseError(GET, requestUrl, resp.StatusCode, body)
 	}
-	if result != nil {
-		// else unmarshall to the result type specified by caller
-		err := xml.Unmarshal(body, &result)
-		if err != nil {
-			return err
-		}
+	_, err = io.Copy(w, resp.Body)
+	return resp.StatusCode, err
+}
+
+func (api *API) newRequest(ctx context.Context, requestUrl string, method string, payload []byte, headers map[string]string) (*http.Request, error) {
+	var body io.Reader
+	if len(payload) > 0 {
+		body = bytes.NewBuffer(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.TrimSpace(method), strings.TrimSpace(requestUrl), body)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > 0 {
+		req.Header.Add(content_length_header, strconv.Itoa(len(payload)))
+	}
+	for header, headerValue := range headers {
+		req.Header.Add(header, headerValue)
+	}
+	if authToken := api.AuthToken(); len(authToken) > 0 {
+		req.Header.Add(auth_header, authToken)
 	}
-	return nil
+	return req, nil
 }
@@ -0,0 +1,134 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+	"github.com/mattbaird/tableau4go/tableautest"
+)
+
+func TestQuerySites(t *testing.T) {
+	server, api := signedIn(t)
+	other := server.AddSite(tableau4go.Site{Name: "Other", ContentUrl: "other"})
+	sites, err := api.QuerySites()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 2 || sites[0].Name != tableautest.DEFAULT_SITE_NAME || sites[1].ID != other.ID || sites[1].ContentUrl != "other" {
+		t.Errorf("QuerySites() = %+v", sites)
+	}
+}
+
+func TestCreateProject(t *testing.T) {
+	server, api := signedIn(t)
+	created, err := api.CreateProject(api.SiteID(), tableau4go.Project{Name: "Finance", Description: "ledgers"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created == nil || len(created.ID) == 0 || created.Name != "Finance" || created.Description != "ledgers" {
+		t.Fatalf("CreateProject() = %+v", created)
+	}
+	found, err := api.GetProjectByName(api.SiteID(), "Finance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != created.ID {
+		t.Errorf("GetProjectByName found %s, created %s", found.ID, created.ID)
+	}
+	if got := len(server.Projects(api.SiteID())); got != 2 {
+		t.Errorf("%d projects on the server, want 2", got)
+	}
+}
+
+func TestPublishTDS(t *testing.T) {
+	server, api := signedIn(t)
+	project, err := api.CreateProject(api.SiteID(), tableau4go.Project{Name: "Finance"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tds := "<?xml version='1.0' encoding='utf-8' ?><datasource version='10.0'></datasource>"
+	published, err := api.PublishTDS(api.SiteID(), tableau4go.Datasource{Name: "Ledger", Project: &tableau4go.Project{ID: project.ID}}, tds, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published == nil || len(published.ID) == 0 || published.Name != "Ledger" {
+		t.Fatalf("PublishTDS() = %+v", published)
+	}
+	if published.Project == nil || published.Project.ID != project.ID {
+		t.Errorf("published into %+v, want project %s", published.Project, project.ID)
+	}
+	if content := string(server.DatasourceContent(api.SiteID(), published.ID)); content != tds {
+		t.Errorf("server holds %q", content)
+	}
+}
+
+// TestFormatsAgainstTheFake makes the same calls in xml and in json and expects
+// the same structs and errors back.
+func TestFormatsAgainstTheFake(t *testing.T) {
+	type outcome struct {
+		Info        tableau4go.ServerInfo
+		Sites       []tableau4go.Site
+		Published   *tableau4go.Datasource
+		Datasources []tableau4go.Datasource
+		Conflict    string
+	}
+	run := func(format string) outcome {
+		server, _ := signedIn(t)
+		api := server.API()
+		api.Format = format
+		if err := api.Signin("admin", "secret", "", ""); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		var o outcome
+		var err error
+		if o.Info, err = api.ServerInfo(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if o.Sites, err = api.QuerySites(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if o.Published, err = api.PublishTDS(api.SiteID(), tableau4go.Datasource{Name: "Ledger"}, "<datasource/>", false); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if o.Datasources, err = api.QueryDatasources(api.SiteID()); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		_, err = api.CreateProject(api.SiteID(), tableau4go.Project{Name: tableautest.DEFAULT_PROJECT_NAME})
+		var terror tableau4go.Terror
+		if !errors.As(err, &terror) {
+			t.Fatalf("%s: conflict came back as %v", format, err)
+		}
+		o.Conflict = terror.Code
+		// ids and times differ from one server to the next
+		for i := range o.Sites {
+			o.Sites[i].ID = ""
+		}
+		for _, ds := range append(o.Datasources, *o.Published) {
+			if ds.Size != int64(len("<datasource/>")) || ds.CreatedAt == nil {
+				t.Errorf("%s: datasource %+v", format, ds)
+			}
+		}
+		o.Published, o.Datasources = nil, nil
+		return o
+	}
+	fromXML, fromJSON := run(tableau4go.FORMAT_XML), run(tableau4go.FORMAT_JSON)
+	if !reflect.DeepEqual(fromXML, fromJSON) {
+		t.Errorf("xml and json differ\nxml:  %+v\njson: %+v", fromXML, fromJSON)
+	}
+	if fromJSON.Info.ProductVersion != tableautest.PRODUCT_VERSION {
+		t.Errorf("json ServerInfo = %+v", fromJSON.Info)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinels for errors.Is. Every non 2xx response comes back as an *APIError,
+// which matches the sentinel for its status, e.g.
+//
+//	if errors.Is(err, tableau4go.ErrConflict) { // 409009, project already exists
+//
+// Lookups done on the client side, such as GetProjectByName, wrap
+// ErrDoesNotExist, so errors.Is(err, ErrDoesNotExist) covers not found either
+// way.
+var (
+	ErrDoesNotExist = errors.New("Does Not Exist")
+	ErrNotFound     = ErrDoesNotExist
+	ErrUnauthorized = errors.New("Unauthorized")
+	ErrForbidden    = errors.New("Forbidden")
+	ErrConflict     = errors.New("Conflict")
+	ErrRateLimited  = errors.New("Rate Limited")
+)
+
+// how much of an unparseable error body is kept on the APIError
+const error_body_snippet_length = 512
+
+// APIError is returned for any response with a status of 300 or above. Code,
+// Summary and Detail come from the Tableau error body when there is one; when
+// the body is not a Tableau error (an html page from a proxy, say) Body holds
+// the start of it and Unwrap returns the decoding error.
+//
+// It takes the place of the bare Terror and ErrDoesNotExist that were returned
+// before, so err == ErrDoesNotExist and err.(Terror) are now false for a 404:
+// use errors.Is for the sentinels and errors.As(err, &terror) for the Terror.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Summary    string
+	Detail     string
+	Method     string
+	URL        string
+	Body       string
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	if len(e.Code) > 0 {
+		return fmt.Sprintf("%s %s: %d %s, Code:%s, Summary:%s, Detail:%s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Code, e.Summary, e.Detail)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: %d %s: undecodable error body %q: %v", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Body, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Is(target error) bool {
+	sentinel := statusSentinel(e.StatusCode, e.Code)
+	return sentinel != nil && sentinel == target
+}
+
+// As lets code written against the old bare Terror keep working with errors.As.
+func (e *APIError) As(target interface{}) bool {
+	if t, ok := target.(*Terror); ok && len(e.Code) > 0 {
+		*t = e.Terror()
+		return true
+	}
+	return false
+}
+
+func (e *APIError) Terror() Terror {
+	return Terror{Code: e.Code, Summary: e.Summary, Detail: e.Detail}
+}
+
+// statusSentinel maps a status, or failing that the first three digits of a
+// Tableau error code (401002 -> 401), to its sentinel.
+func statusSentinel(statusCode int, code string) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrDoesNotExist
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	if len(code) >= 3 {
+		switch code[:3] {
+		case "401":
+			return ErrUnauthorized
+		case "403":
+			return ErrForbidden
+		case "404":
+			return ErrDoesNotExist
+		case "409":
+			return ErrConflict
+		case "429":
+			return ErrRateLimited
+		}
+	}
+	return nil
+}
+
+func responseError(method string, requestUrl string, statusCode int, body []byte) error {
+	apiErr := &APIError{StatusCode: statusCode, Method: method, URL: requestUrl}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return apiErr
+	}
+	tErrorResponse := ErrorResponse{}
+	err := xml.Unmarshal(body, &tErrorResponse)
+	if err != nil {
+		// the services that speak json wrap the same fields in {"error": {...}}
+		if jsonErr := json.Unmarshal(body, &tErrorResponse); jsonErr == nil && len(tErrorResponse.Error.Code) > 0 {
+			err = nil
+		}
+	}
+	if err != nil || len(tErrorResponse.Error.Code)+len(tErrorResponse.Error.Summary) == 0 {
+		apiErr.Body = snippet(body)
+		if err == nil {
+			err = errors.New("no Tableau error in response body")
+		}
+		apiErr.Err = err
+		return apiErr
+	}
+	apiErr.Code = tErrorResponse.Error.Code
+	apiErr.Summary = tErrorResponse.Error.Summary
+	apiErr.Detail = tErrorResponse.Error.Detail
+	return apiErr
+}
+
+// decodeError wraps a failure to decode a successful response with where it came from.
+func decodeError(method string, requestUrl string, body []byte, err error) error {
+	return fmt.Errorf("%s %s: decoding response %q: %w", method, requestUrl, snippet(body), err)
+}
+
+func snippet(body []byte) string {
+	if len(body) > error_body_snippet_length {
+		return string(body[:error_body_snippet_length]) + "..."
+	}
+	return string(body)
+}
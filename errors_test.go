@@ -0,0 +1,72 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+	"github.com/mattbaird/tableau4go/tableautest"
+)
+
+// signedIn returns a fake server with an admin on the Default site and an API
+// signed in as them.
+func signedIn(t *testing.T) (*tableautest.Server, *tableau4go.API) {
+	t.Helper()
+	server := tableautest.NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin", SiteRole: "ServerAdministrator"}, "secret")
+	api := server.API()
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	return server, api
+}
+
+func TestNotFoundIsOneCheck(t *testing.T) {
+	_, api := signedIn(t)
+	err := api.DeleteProject(api.SiteID(), "no-such-project")
+	if !errors.Is(err, tableau4go.ErrDoesNotExist) {
+		t.Errorf("DeleteProject: %v is not ErrDoesNotExist", err)
+	}
+	var apiErr *tableau4go.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != tableautest.ERROR_PROJECT_NOT_FOUND {
+		t.Errorf("DeleteProject: %#v", apiErr)
+	}
+	var terror tableau4go.Terror
+	if !errors.As(err, &terror) || terror.Code != tableautest.ERROR_PROJECT_NOT_FOUND {
+		t.Errorf("errors.As Terror: %#v", terror)
+	}
+	if _, err := api.GetProjectByName(api.SiteID(), "no-such-project"); !errors.Is(err, tableau4go.ErrDoesNotExist) {
+		t.Errorf("GetProjectByName: %v is not ErrDoesNotExist", err)
+	}
+	if _, err := api.GetProjectByID(api.SiteID(), "no-such-project"); !errors.Is(err, tableau4go.ErrNotFound) {
+		t.Errorf("GetProjectByID: %v is not ErrNotFound", err)
+	}
+}
+
+func TestConflictAndUnauthorized(t *testing.T) {
+	_, api := signedIn(t)
+	_, err := api.CreateProject(api.SiteID(), tableau4go.Project{Name: tableautest.DEFAULT_PROJECT_NAME})
+	if !errors.Is(err, tableau4go.ErrConflict) {
+		t.Errorf("CreateProject: %v is not ErrConflict", err)
+	}
+	if errors.Is(err, tableau4go.ErrDoesNotExist) {
+		t.Errorf("CreateProject: %v matched ErrDoesNotExist", err)
+	}
+	api.SetAuthToken("stale", api.SiteID())
+	if _, err := api.QueryProjects(api.SiteID()); !errors.Is(err, tableau4go.ErrUnauthorized) {
+		t.Errorf("QueryProjects: %v is not ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SiteResult struct {
+	Site     Site
+	Err      error
+	Duration time.Duration
+}
+
+// FanOutReport has one result per site, in the order QuerySites returned them.
+type FanOutReport struct {
+	Results []SiteResult
+}
+
+func (r FanOutReport) Failed() []SiteResult {
+	failed := []SiteResult{}
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err is nil when every site succeeded, otherwise a SiteErrors.
+func (r FanOutReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return SiteErrors(failed)
+}
+
+type SiteErrors []SiteResult
+
+func (errs SiteErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, result := range errs {
+		messages = append(messages, fmt.Sprintf("%s: %v", result.Site.ContentUrl, result.Err))
+	}
+	return fmt.Sprintf("%d site(s) failed: %s", len(errs), strings.Join(messages, "; "))
+}
+
+// ForEachSite signs in, lists every site and calls fn for each one with a session
+// switched into that site, running at most concurrency sites at a time. A token is
+// only ever scoped to one site, so each worker keeps its own session, signed in
+// once and switched from site to site. The first worker carries on with the
+// session that listed the sites, so there are no more password sign ins than
+// workers. fn gets a clone of the worker's session holding the same token, so a
+// Signout or SwitchSite on it spends that token and the worker signs in again
+// before its next site. Failures (including a site that cannot be switched into
+// because it is locked or suspended, and a panic in fn) are recorded in the
+// report and the remaining sites still run. The returned error is only for
+// failing to list the sites in the first place.
+func (api *API) ForEachSite(username, password string, concurrency int, fn func(siteApi *API, site Site) error) (FanOutReport, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	lister := api.Clone()
+	err := lister.Signin(username, password, lister.DefaultSiteName, "")
+	if err != nil {
+		return FanOutReport{}, err
+	}
+	sites, err := lister.QuerySites()
+	if err != nil {
+		lister.Signout()
+		return FanOutReport{}, err
+	}
+	report := FanOutReport{Results: make([]SiteResult, len(sites))}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(sites); w++ {
+		worker := lister
+		if w > 0 {
+			worker = api.Clone()
+		}
+		wg.Add(1)
+		go func(worker *API, signedIn bool) {
+			defer wg.Done()
+			var signinErr error
+			for i := range jobs {
+				start := time.Now()
+				if !signedIn && signinErr == nil {
+					signinErr = worker.Signin(username, password, worker.DefaultSiteName, "")
+					signedIn = signinErr == nil
+				}
+				err := signinErr
+				if signedIn {
+					var spent bool
+					spent, err = worker.runOnSite(sites[i], fn)
+					signedIn = !spent
+				}
+				report.Results[i] = SiteResult{Site: sites[i], Err: err, Duration: time.Since(start)}
+			}
+			if signedIn {
+				worker.Signout()
+			}
+		}(worker, w == 0)
+	}
+	for i := range sites {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if len(sites) == 0 {
+		lister.Signout()
+	}
+	return report, nil
+}
+
+// runOnSite switches api into site and calls fn with a clone of its session.
+// spent reports that fn moved the clone off the token they shared, which a
+// Signout or SwitchSite ends on the server for api as well.
+func (api *API) runOnSite(site Site, fn func(siteApi *API, site Site) error) (spent bool, err error) {
+	// the server refuses a switch to the site the token is already on
+	if api.SiteID() != site.ID {
+		if err := api.SwitchSite(site.ContentUrl); err != nil {
+			return false, err
+		}
+	}
+	token := api.AuthToken()
+	siteApi := api.Clone()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic on site '%s': %v", site.ContentUrl, r)
+		}
+		spent = siteApi.AuthToken() != token
+	}()
+	return false, fn(siteApi, site)
+}
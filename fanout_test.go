@@ -0,0 +1,151 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+	"github.com/mattbaird/tableau4go/tableautest"
+)
+
+// fanOutServer has the Default site plus one site per name, given a state when
+// the name is a key of states, with admin on all of them.
+func fanOutServer(t *testing.T, names []string, states map[string]string) *tableautest.Server {
+	t.Helper()
+	server := tableautest.NewServer()
+	t.Cleanup(server.Close)
+	admin := tableau4go.User{Name: "admin", SiteRole: "ServerAdministrator"}
+	server.AddUser(server.DefaultSite().ID, admin, "secret")
+	for _, name := range names {
+		st := server.AddSite(tableau4go.Site{Name: name, ContentUrl: strings.ToLower(name), State: states[name]})
+		server.AddUser(st.ID, admin, "secret")
+	}
+	return server
+}
+
+func TestForEachSiteSignsInOncePerWorker(t *testing.T) {
+	server := fanOutServer(t, []string{"Alpha", "Beta", "Gamma", "Delta"}, nil)
+	var signins int32
+	api := server.API()
+	api.HTTPClient = &http.Client{Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/auth/signin") {
+			atomic.AddInt32(&signins, 1)
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	var mu sync.Mutex
+	visited := make(map[string]bool)
+	report, err := api.ForEachSite("admin", "secret", 2, func(siteApi *tableau4go.API, site tableau4go.Site) error {
+		if siteApi.SiteID() != site.ID {
+			t.Errorf("session for %s is on site %s", site.ID, siteApi.SiteID())
+		}
+		_, err := siteApi.QueryProjects(site.ID)
+		mu.Lock()
+		visited[site.ID] = true
+		mu.Unlock()
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := report.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(visited) != 5 {
+		t.Errorf("visited %d sites, want 5", len(visited))
+	}
+	if n := atomic.LoadInt32(&signins); n != 2 {
+		t.Errorf("signed in %d times with 2 workers, want 2", n)
+	}
+}
+
+func TestForEachSiteReportsUnavailableSites(t *testing.T) {
+	server := fanOutServer(t, []string{"Alpha", "Locked", "Gamma", "Delta"}, map[string]string{"Locked": "Suspended"})
+	api := server.API()
+	report, err := api.ForEachSite("admin", "secret", 2, func(siteApi *tableau4go.API, site tableau4go.Site) error {
+		_, err := siteApi.QueryProjects(site.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 5 {
+		t.Fatalf("%d results, want 5", len(report.Results))
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Site.ContentUrl != "locked" {
+		t.Fatalf("Failed() = %+v, want the locked site alone", failed)
+	}
+	var apiErr *tableau4go.APIError
+	if !errors.As(failed[0].Err, &apiErr) || apiErr.Code != tableautest.ERROR_SITE_UNAVAILABLE {
+		t.Errorf("locked site failed with %v", failed[0].Err)
+	}
+	var siteErrs tableau4go.SiteErrors
+	if !errors.As(report.Err(), &siteErrs) || len(siteErrs) != 1 || !strings.Contains(report.Err().Error(), "locked:") {
+		t.Errorf("Err() = %v", report.Err())
+	}
+	for i, want := range []string{"", "alpha", "locked", "gamma", "delta"} {
+		result := report.Results[i]
+		if result.Site.ContentUrl != want {
+			t.Errorf("result %d is for %q, want %q", i, result.Site.ContentUrl, want)
+		}
+		if want != "locked" && result.Err != nil {
+			t.Errorf("%s failed: %v", want, result.Err)
+		}
+	}
+}
+
+func TestForEachSiteRecoversPanics(t *testing.T) {
+	server := fanOutServer(t, []string{"Alpha", "Beta"}, nil)
+	report, err := server.API().ForEachSite("admin", "secret", 1, func(siteApi *tableau4go.API, site tableau4go.Site) error {
+		if site.ContentUrl == "alpha" {
+			panic("boom")
+		}
+		_, err := siteApi.QueryProjects(site.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Site.ContentUrl != "alpha" || !strings.Contains(failed[0].Err.Error(), "panic on site 'alpha': boom") {
+		t.Fatalf("Failed() = %+v, want the panic on alpha", failed)
+	}
+	// the worker that panicked carried on with the next site
+	if len(report.Results) != 3 || report.Results[2].Err != nil {
+		t.Errorf("results = %+v", report.Results)
+	}
+}
+
+// TestForEachSiteSurvivesASpentToken has fn sign out the session it was given,
+// which ends the token the worker holds as well.
+func TestForEachSiteSurvivesASpentToken(t *testing.T) {
+	server := fanOutServer(t, []string{"Alpha", "Beta", "Gamma"}, nil)
+	report, err := server.API().ForEachSite("admin", "secret", 1, func(siteApi *tableau4go.API, site tableau4go.Site) error {
+		if _, err := siteApi.QueryProjects(site.ID); err != nil {
+			return err
+		}
+		return siteApi.Signout()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := report.Err(); err != nil {
+		t.Error(err)
+	}
+}
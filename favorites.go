@@ -0,0 +1,93 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#add_workbook_to_favorites
+func (api *API) AddWorkbookToFavorites(siteId, userId, label, workbookId string) ([]Favorite, error) {
+	return api.addFavorite(siteId, userId, Favorite{Label: label, Workbook: &Workbook{ID: workbookId}})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#add_view_to_favorites
+func (api *API) AddViewToFavorites(siteId, userId, label, viewId string) ([]Favorite, error) {
+	return api.addFavorite(siteId, userId, Favorite{Label: label, View: &View{ID: viewId}})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#add_data_source_to_favorites
+func (api *API) AddDatasourceToFavorites(siteId, userId, label, datasourceId string) ([]Favorite, error) {
+	return api.addFavorite(siteId, userId, Favorite{Label: label, Datasource: &Datasource{ID: datasourceId}})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#add_project_to_favorites
+func (api *API) AddProjectToFavorites(siteId, userId, label, projectId string) ([]Favorite, error) {
+	return api.addFavorite(siteId, userId, Favorite{Label: label, Project: &Project{ID: projectId}})
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#add_flow_to_favorites
+func (api *API) AddFlowToFavorites(siteId, userId, label, flowId string) ([]Favorite, error) {
+	return api.addFavorite(siteId, userId, Favorite{Label: label, Flow: &Flow{ID: flowId}})
+}
+
+func (api *API) addFavorite(siteId, userId string, favorite Favorite) ([]Favorite, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/favorites/%s", api.Server, api.Version, siteId, userId)
+	addFavoriteRequest := AddFavoriteRequest{Request: favorite}
+	headers := make(map[string]string)
+	payload, err := api.encode(addFavoriteRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := FavoritesResponse{}
+	err = api.makeRequest(url, PUT, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Favorites.Favorites, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#delete_workbook_from_favorites
+func (api *API) DeleteWorkbookFromFavorites(siteId, userId, workbookId string) error {
+	return api.deleteFavorite(siteId, userId, "workbooks", workbookId)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#delete_view_from_favorites
+func (api *API) DeleteViewFromFavorites(siteId, userId, viewId string) error {
+	return api.deleteFavorite(siteId, userId, "views", viewId)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#delete_data_source_from_favorites
+func (api *API) DeleteDatasourceFromFavorites(siteId, userId, datasourceId string) error {
+	return api.deleteFavorite(siteId, userId, "datasources", datasourceId)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#delete_project_from_favorites
+func (api *API) DeleteProjectFromFavorites(siteId, userId, projectId string) error {
+	return api.deleteFavorite(siteId, userId, "projects", projectId)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#delete_flow_from_favorites
+func (api *API) DeleteFlowFromFavorites(siteId, userId, flowId string) error {
+	return api.deleteFavorite(siteId, userId, "flows", flowId)
+}
+
+func (api *API) deleteFavorite(siteId, userId, contentType, contentId string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/favorites/%s/%s/%s", api.Server, api.Version, siteId, userId, contentType, contentId)
+	return api.delete(url)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_favorites.htm#get_favorites_for_user
+func (api *API) QueryFavoritesForUser(siteId, userId string) ([]Favorite, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/favorites/%s", api.Server, api.Version, siteId, userId)
+	headers := make(map[string]string)
+	retval := FavoritesResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Favorites.Favorites, err
+}
@@ -0,0 +1,139 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"io"
+	"path"
+)
+
+const FLOW_FILE_TYPE_TFL = "tfl"
+const FLOW_FILE_TYPE_TFLX = "tflx"
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#query_flows_for_site
+func (api *API) QueryFlows(siteId string) ([]Flow, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QueryFlowsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Flows.Flows, err
+}
+
+// QueryFlow returns the flow along with its output steps.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#query_flow
+func (api *API) QueryFlow(siteId string, flowId string) (Flow, []FlowOutputStep, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows/%s", api.Server, api.Version, siteId, flowId)
+	headers := make(map[string]string)
+	retval := FlowResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Flow, retval.FlowOutputSteps.FlowOutputSteps, err
+}
+
+// PublishFlow uploads a .tfl or .tflx, the file type is taken from fileName.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#publish_flow
+func (api *API) PublishFlow(siteId string, flowMetadata Flow, fileName string, flow []byte, overwrite bool) (*Flow, error) {
+	fileType := path.Ext(fileName)
+	if len(fileType) > 0 {
+		fileType = fileType[1:]
+	}
+	if fileType != FLOW_FILE_TYPE_TFL && fileType != FLOW_FILE_TYPE_TFLX {
+		return nil, fmt.Errorf("Flow file '%s' must be a .%s or .%s", fileName, FLOW_FILE_TYPE_TFL, FLOW_FILE_TYPE_TFLX)
+	}
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows?flowType=%s&overwrite=%v", api.Server, api.Version, siteId, fileType, overwrite)
+	flowRequest := FlowCreateRequest{Request: flowMetadata}
+	requestPayload, err := api.encode(flowRequest, make(map[string]string))
+	if err != nil {
+		return nil, err
+	}
+	payload := api.multipartPayload(requestPayload, "tableau_flow", fileName, flow)
+	headers := make(map[string]string)
+	headers[content_type_header] = fmt.Sprintf("multipart/mixed; boundary=%s", api.Boundary)
+	retval := FlowResponse{}
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Flow, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#download_flow
+func (api *API) DownloadFlow(siteId string, flowId string, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows/%s/content", api.Server, api.Version, siteId, flowId)
+	return api.download(url, w, make(map[string]string))
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#delete_flow
+func (api *API) DeleteFlow(siteId string, flowId string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows/%s", api.Server, api.Version, siteId, flowId)
+	return api.delete(url)
+}
+
+// RunFlowNow queues the flow and returns the job, poll it with QueryJob.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#run_flow_now
+func (api *API) RunFlowNow(siteId string, flowId string) (*Job, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows/%s/run", api.Server, api.Version, siteId, flowId)
+	headers := make(map[string]string)
+	payload, err := api.encode(emptyRequest{}, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := JobResponse{}
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Job, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#get_flow_runs
+func (api *API) QueryFlowRuns(siteId string) ([]FlowRun, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows/runs", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QueryFlowRunsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.FlowRuns.FlowRuns, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#get_flow_run
+func (api *API) QueryFlowRun(siteId string, flowRunId string) (FlowRun, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/flows/runs/%s", api.Server, api.Version, siteId, flowRunId)
+	headers := make(map[string]string)
+	retval := FlowRunResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.FlowRun, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#get_flow_run_tasks
+func (api *API) QueryFlowRunTasks(siteId string) ([]FlowRunTask, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/tasks/runFlow", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QueryFlowRunTasksResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	tasks := make([]FlowRunTask, 0, len(retval.Tasks.Tasks))
+	for _, task := range retval.Tasks.Tasks {
+		tasks = append(tasks, task.FlowRun)
+	}
+	return tasks, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#get_flow_run_task
+func (api *API) QueryFlowRunTask(siteId string, taskId string) (FlowRunTask, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/tasks/runFlow/%s", api.Server, api.Version, siteId, taskId)
+	headers := make(map[string]string)
+	retval := FlowRunTaskResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Task.FlowRun, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_jobs_tasks_and_schedules.htm#query_job
+func (api *API) QueryJob(siteId string, jobId string) (Job, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/jobs/%s", api.Server, api.Version, siteId, jobId)
+	headers := make(map[string]string)
+	retval := JobResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Job, err
+}
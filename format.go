@@ -0,0 +1,198 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+)
+
+// The wire formats of the REST API, see API.Format. The models carry tags for
+// both, so either decodes to the same structs.
+const FORMAT_XML = "xml"
+const FORMAT_JSON = "json"
+
+const accept_header = "Accept"
+
+// tsRequest is implemented by the request wrappers, which marshal themselves
+// inside <tsRequest>. In json the wrapper is the top level object.
+type tsRequest interface {
+	XML() ([]byte, error)
+}
+
+// emptyRequest is the body of calls that POST nothing but still want one.
+type emptyRequest struct{}
+
+func (req emptyRequest) XML() ([]byte, error) {
+	return []byte("<tsRequest></tsRequest>"), nil
+}
+
+func (api *API) jsonFormat() bool {
+	return api.Format == FORMAT_JSON
+}
+
+// mediaType is the content type of request and response bodies in api's format.
+func (api *API) mediaType() string {
+	if api.jsonFormat() {
+		return application_json_content_type
+	}
+	return application_xml_content_type
+}
+
+// encode serializes request in api's format and sets the content type to match.
+func (api *API) encode(request tsRequest, headers map[string]string) ([]byte, error) {
+	headers[content_type_header] = api.mediaType()
+	if api.jsonFormat() {
+		return json.Marshal(request)
+	}
+	return request.XML()
+}
+
+func (api *API) decode(body []byte, result interface{}) error {
+	if api.jsonFormat() {
+		return json.Unmarshal(body, result)
+	}
+	return xml.Unmarshal(body, result)
+}
+
+// Tableau Server writes the numbers of its json as strings ("size": "1024"), as
+// they are attributes in xml. The models tag those fields ,string so they go out
+// the same way, and the types below decode them quoted or not.
+
+func (ds *Datasource) UnmarshalJSON(data []byte) error {
+	type plain Datasource
+	return unmarshalQuoted(data, (*plain)(ds))
+}
+
+func (wb *Workbook) UnmarshalJSON(data []byte) error {
+	type plain Workbook
+	return unmarshalQuoted(data, (*plain)(wb))
+}
+
+func (usage *ViewUsage) UnmarshalJSON(data []byte) error {
+	type plain ViewUsage
+	return unmarshalQuoted(data, (*plain)(usage))
+}
+
+func (run *FlowRun) UnmarshalJSON(data []byte) error {
+	type plain FlowRun
+	return unmarshalQuoted(data, (*plain)(run))
+}
+
+func (task *FlowRunTask) UnmarshalJSON(data []byte) error {
+	type plain FlowRunTask
+	return unmarshalQuoted(data, (*plain)(task))
+}
+
+func (job *Job) UnmarshalJSON(data []byte) error {
+	type plain Job
+	return unmarshalQuoted(data, (*plain)(job))
+}
+
+func (schedule *Schedule) UnmarshalJSON(data []byte) error {
+	type plain Schedule
+	return unmarshalQuoted(data, (*plain)(schedule))
+}
+
+func (result *WebhookTestResult) UnmarshalJSON(data []byte) error {
+	type plain WebhookTestResult
+	return unmarshalQuoted(data, (*plain)(result))
+}
+
+func (site *Site) UnmarshalJSON(data []byte) error {
+	type plain Site
+	return unmarshalQuoted(data, (*plain)(site))
+}
+
+func (usage *SiteUsage) UnmarshalJSON(data []byte) error {
+	type plain SiteUsage
+	return unmarshalQuoted(data, (*plain)(usage))
+}
+
+// unmarshalQuoted quotes any bare number given for a ,string field of v before
+// decoding, which json would otherwise refuse.
+func unmarshalQuoted(data []byte, v interface{}) error {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// not an object, let json report it
+		return json.Unmarshal(data, v)
+	}
+	quoted := false
+	structType := reflect.TypeOf(v).Elem()
+	for i := 0; i < structType.NumField(); i++ {
+		options := strings.Split(structType.Field(i).Tag.Get("json"), ",")
+		if len(options) < 2 || options[1] != "string" {
+			continue
+		}
+		raw, ok := fields[options[0]]
+		if ok && len(raw) > 0 && (raw[0] == '-' || (raw[0] >= '0' && raw[0] <= '9')) {
+			fields[options[0]] = json.RawMessage(`"` + string(raw) + `"`)
+			quoted = true
+		}
+	}
+	if !quoted {
+		return json.Unmarshal(data, v)
+	}
+	requoted, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(requoted, v)
+}
+
+// productVersion is how the json serverInfo carries the version, which in xml
+// is the text of <productVersion build="...">.
+type productVersion struct {
+	Value string `json:"value"`
+	Build string `json:"build,omitempty"`
+}
+
+func (info ServerInfo) MarshalJSON() ([]byte, error) {
+	type plain ServerInfo
+	return json.Marshal(struct {
+		plain
+		ProductVersion *productVersion `json:"productVersion,omitempty"`
+	}{plain: plain(info), ProductVersion: versionOf(info.ProductVersion)})
+}
+
+func (info *ServerInfo) UnmarshalJSON(data []byte) error {
+	type plain ServerInfo
+	decoded := struct {
+		*plain
+		ProductVersion json.RawMessage `json:"productVersion,omitempty"`
+	}{plain: (*plain)(info)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if len(decoded.ProductVersion) == 0 || string(decoded.ProductVersion) == "null" {
+		return nil
+	}
+	// a plain string, as the model used to have it, is taken as it is
+	if decoded.ProductVersion[0] == '"' {
+		return json.Unmarshal(decoded.ProductVersion, &info.ProductVersion)
+	}
+	version := productVersion{}
+	if err := json.Unmarshal(decoded.ProductVersion, &version); err != nil {
+		return err
+	}
+	info.ProductVersion = version.Value
+	return nil
+}
+
+func versionOf(value string) *productVersion {
+	if len(value) == 0 {
+		return nil
+	}
+	return &productVersion{Value: value}
+}
@@ -0,0 +1,151 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The bodies in testdata/responses follow the examples of the REST API
+// reference, one response in both formats.
+var responseBodies = []struct {
+	name   string
+	result func() interface{}
+	check  func(v interface{}) bool
+}{
+	{"serverinfo", func() interface{} { return &ServerInfoResponse{} }, func(v interface{}) bool {
+		info := v.(*ServerInfoResponse).ServerInfo
+		return info.ProductVersion == "2023.3.0" && info.RestApiVersion == "3.21"
+	}},
+	{"signin", func() interface{} { return &AuthResponse{} }, func(v interface{}) bool {
+		credentials := v.(*AuthResponse).Credentials
+		return credentials != nil && len(credentials.Token) > 0 && credentials.Site.ContentUrl == "finance" && len(credentials.Impersonate.ID) > 0
+	}},
+	{"sites", func() interface{} { return &QuerySitesResponse{} }, func(v interface{}) bool {
+		sites := v.(*QuerySitesResponse).Sites.Sites
+		return len(sites) == 2 && sites[0].StorageQuota == 1000 && sites[0].RevisionLimit == 25 &&
+			*sites[0].RevisionHistoryEnabled && !*sites[1].RevisionHistoryEnabled && sites[1].UserQuota == "50"
+	}},
+	{"site", func() interface{} { return &QuerySiteResponse{} }, func(v interface{}) bool {
+		site := v.(*QuerySiteResponse).Site
+		return site.Usage != nil && site.Usage.NumberOfUsers == 12 && site.Usage.Storage == 4096
+	}},
+	{"projects", func() interface{} { return &QueryProjectsResponse{} }, func(v interface{}) bool {
+		return len(v.(*QueryProjectsResponse).Projects.Projects) == 2
+	}},
+	{"datasources", func() interface{} { return &QueryDatasourcesResponse{} }, func(v interface{}) bool {
+		ds := v.(*QueryDatasourcesResponse).Datasources.Datasources
+		return len(ds) == 1 && ds[0].Size == 2048 && ds[0].IsCertified && ds[0].HasExtracts && ds[0].CreatedAt != nil &&
+			ds[0].Project.Name == "Finance" && len(ds[0].Tags.Labels()) == 1
+	}},
+	{"views", func() interface{} { return &QueryViewsResponse{} }, func(v interface{}) bool {
+		views := v.(*QueryViewsResponse).Views.Views
+		return len(views) == 1 && views[0].Usage != nil && views[0].Usage.TotalViewCount == 137 && views[0].Workbook != nil
+	}},
+	{"job", func() interface{} { return &JobResponse{} }, func(v interface{}) bool {
+		job := v.(*JobResponse).Job
+		return job.Progress == 100 && job.Succeeded() && job.FlowRun != nil && job.FlowRun.Progress == 100
+	}},
+	{"flowruntasks", func() interface{} { return &QueryFlowRunTasksResponse{} }, func(v interface{}) bool {
+		tasks := v.(*QueryFlowRunTasksResponse).Tasks.Tasks
+		return len(tasks) == 1 && tasks[0].FlowRun.Priority == 50 && tasks[0].FlowRun.Schedule.Priority == 50 && tasks[0].FlowRun.Schedule.NextRunAt != nil
+	}},
+	{"webhooktest", func() interface{} { return &TestWebhookResponse{} }, func(v interface{}) bool {
+		result := v.(*TestWebhookResponse).WebhookTestResult
+		return result.Status == 200 && result.Body == "ok"
+	}},
+	{"error", func() interface{} { return &ErrorResponse{} }, func(v interface{}) bool {
+		terror := v.(*ErrorResponse).Error
+		return terror.Code == "404005" && terror.Summary == "Resource Not Found" && len(terror.Detail) > 0
+	}},
+}
+
+func readResponse(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := ioutil.ReadFile(filepath.Join("testdata", "responses", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestFormatsDecodeToTheSameStructs(t *testing.T) {
+	xmlApi := &API{Format: FORMAT_XML}
+	jsonApi := &API{Format: FORMAT_JSON}
+	for _, c := range responseBodies {
+		fromXML, fromJSON := c.result(), c.result()
+		if err := xmlApi.decode(readResponse(t, c.name+".xml"), fromXML); err != nil {
+			t.Errorf("%s.xml: %v", c.name, err)
+			continue
+		}
+		if err := jsonApi.decode(readResponse(t, c.name+".json"), fromJSON); err != nil {
+			t.Errorf("%s.json: %v", c.name, err)
+			continue
+		}
+		if !c.check(fromXML) {
+			t.Errorf("%s.xml decoded to %+v", c.name, fromXML)
+		}
+		if !reflect.DeepEqual(fromXML, fromJSON) {
+			t.Errorf("%s: xml and json differ\nxml:  %+v\njson: %+v", c.name, fromXML, fromJSON)
+		}
+	}
+}
+
+func TestJSONNumbersQuotedOrNot(t *testing.T) {
+	quoted := readResponse(t, "sites.json")
+	bare := strings.NewReplacer(`"storageQuota":"1000"`, `"storageQuota":1000`, `"revisionLimit":"25"`, `"revisionLimit":25`).Replace(string(quoted))
+	fromQuoted, fromBare := QuerySitesResponse{}, QuerySitesResponse{}
+	if err := json.Unmarshal(quoted, &fromQuoted); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(bare), &fromBare); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromQuoted, fromBare) {
+		t.Errorf("bare numbers decoded to %+v, quoted to %+v", fromBare, fromQuoted)
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(`{"progress":-1,"finishCode":1}`), &job); err != nil || job.Progress != -1 || job.FinishCode == nil || *job.FinishCode != 1 {
+		t.Errorf("bare job numbers: %+v, %v", job, err)
+	}
+	if err := json.Unmarshal([]byte(`{"size":"many"}`), &Datasource{}); err == nil {
+		t.Error("a size that is not a number decoded")
+	}
+}
+
+func TestJSONEncodesLikeTheServer(t *testing.T) {
+	body, err := json.Marshal(SiteRequest{Request: Site{Name: "Finance", StorageQuota: 1000, RevisionLimit: 25, FlowsEnabled: Bool(false)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"storageQuota":"1000"`, `"revisionLimit":"25"`, `"flowsEnabled":false`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("%s lacks %s", body, want)
+		}
+	}
+	body, err = json.Marshal(ServerInfoResponse{ServerInfo: ServerInfo{ProductVersion: "2023.3.0", RestApiVersion: "3.21"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"serverInfo":{"restApiVersion":"3.21","productVersion":{"value":"2023.3.0"}}}`; string(body) != want {
+		t.Errorf("serverInfo = %s, want %s", body, want)
+	}
+	var info ServerInfo
+	if err := json.Unmarshal([]byte(`{"productVersion":"2023.3.0"}`), &info); err != nil || info.ProductVersion != "2023.3.0" {
+		t.Errorf("string productVersion: %+v, %v", info, err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/mattbaird/tableau4go
+
+go 1.23.0
@@ -0,0 +1,220 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const LINEAGE_DATABASE = "database"
+const LINEAGE_TABLE = "table"
+const LINEAGE_DATASOURCE = "datasource"
+const LINEAGE_WORKBOOK = "workbook"
+const LINEAGE_VIEW = "view"
+
+// LineageEdge points from the content that is read to the content reading it,
+// using LineageKey values, e.g. table:<id> -> datasource:<luid>.
+type LineageEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// LineageGraph is everything upstream (databases) and downstream (datasources,
+// workbooks, views) of one or more tables. Ids on the content are REST luids so
+// they can be passed straight to the other API calls.
+type LineageGraph struct {
+	Databases   []MetadataDatabase `json:"databases"`
+	Tables      []MetadataTable    `json:"tables"`
+	Datasources []Datasource       `json:"datasources"`
+	Workbooks   []Workbook         `json:"workbooks"`
+	Views       []View             `json:"views"`
+	Owners      []User             `json:"owners"`
+	Projects    []Project          `json:"projects"`
+	Edges       []LineageEdge      `json:"edges"`
+	seen        map[string]bool
+}
+
+func LineageKey(kind string, id string) string {
+	return kind + ":" + id
+}
+
+// TableImpact answers "what breaks if this table goes away". schema and database
+// may be blank to match any.
+func (api *API) TableImpact(database, schema, table string) (*LineageGraph, error) {
+	tables, err := api.QueryTableLineage(database, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	return NewLineageGraph(tables), nil
+}
+
+// NewLineageGraph builds the graph from tables returned by QueryTableLineage.
+func NewLineageGraph(tables []MetadataTable) *LineageGraph {
+	g := &LineageGraph{seen: make(map[string]bool)}
+	for _, t := range tables {
+		tableKey := LineageKey(LINEAGE_TABLE, t.ID)
+		if g.add(tableKey) {
+			g.Tables = append(g.Tables, MetadataTable{ID: t.ID, Name: t.Name, Schema: t.Schema, FullName: t.FullName, Database: t.Database})
+		}
+		if t.Database != nil {
+			databaseKey := LineageKey(LINEAGE_DATABASE, t.Database.ID)
+			if g.add(databaseKey) {
+				g.Databases = append(g.Databases, *t.Database)
+			}
+			g.edge(databaseKey, tableKey)
+		}
+		published := make(map[string]bool)
+		for _, ds := range t.DownstreamDatasources {
+			datasourceKey := LineageKey(LINEAGE_DATASOURCE, ds.Luid)
+			published[ds.Luid] = true
+			if g.add(datasourceKey) {
+				g.Datasources = append(g.Datasources, Datasource{
+					ID:          ds.Luid,
+					Name:        ds.Name,
+					IsCertified: ds.IsCertified,
+					Project:     g.project(ds.ProjectName),
+					Owner:       g.owner(ds.Owner),
+				})
+			}
+			g.edge(tableKey, datasourceKey)
+		}
+		for _, wb := range t.DownstreamWorkbooks {
+			workbookKey := LineageKey(LINEAGE_WORKBOOK, wb.Luid)
+			if g.add(workbookKey) {
+				g.Workbooks = append(g.Workbooks, Workbook{
+					ID:      wb.Luid,
+					Name:    wb.Name,
+					Project: g.project(wb.ProjectName),
+					Owner:   g.owner(wb.Owner),
+				})
+			}
+			// a workbook reaches the table through a published datasource or
+			// through its own embedded connection
+			viaDatasource := false
+			for _, ds := range wb.UpstreamDatasources {
+				if published[ds.Luid] {
+					g.edge(LineageKey(LINEAGE_DATASOURCE, ds.Luid), workbookKey)
+					viaDatasource = true
+				}
+			}
+			if !viaDatasource {
+				g.edge(tableKey, workbookKey)
+			}
+			for _, view := range append(wb.Sheets, wb.Dashboards...) {
+				viewId := view.Luid
+				if len(viewId) == 0 {
+					// hidden sheets have no luid
+					viewId = view.ID
+				}
+				viewKey := LineageKey(LINEAGE_VIEW, viewId)
+				if g.add(viewKey) {
+					g.Views = append(g.Views, View{ID: viewId, Name: view.Name, Workbook: &Workbook{ID: wb.Luid, Name: wb.Name}})
+				}
+				g.edge(workbookKey, viewKey)
+			}
+		}
+	}
+	return g
+}
+
+func (g *LineageGraph) add(key string) bool {
+	if g.seen[key] {
+		return false
+	}
+	g.seen[key] = true
+	return true
+}
+
+func (g *LineageGraph) edge(from, to string) {
+	if g.add(from + "->" + to) {
+		g.Edges = append(g.Edges, LineageEdge{From: from, To: to})
+	}
+}
+
+func (g *LineageGraph) project(name string) *Project {
+	if len(name) == 0 {
+		return nil
+	}
+	if g.add("project:" + name) {
+		g.Projects = append(g.Projects, Project{Name: name})
+	}
+	return &Project{Name: name}
+}
+
+func (g *LineageGraph) owner(owner *MetadataUser) *User {
+	if owner == nil {
+		return nil
+	}
+	user := User{ID: owner.Luid, Name: owner.Username, FullName: owner.Name}
+	if g.add("owner:" + owner.Luid) {
+		g.Owners = append(g.Owners, user)
+	}
+	return &user
+}
+
+func (g *LineageGraph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "   ")
+}
+
+// DOT renders the graph for graphviz, e.g. `dot -Tsvg`. Owners and projects are
+// shown in the node labels rather than as nodes of their own.
+func (g *LineageGraph) DOT() string {
+	var buf bytes.Buffer
+	buf.WriteString("digraph lineage {\n")
+	buf.WriteString("  rankdir=LR;\n")
+	for _, db := range g.Databases {
+		writeDOTNode(&buf, LineageKey(LINEAGE_DATABASE, db.ID), "cylinder", db.Name, db.ConnectionType)
+	}
+	for _, t := range g.Tables {
+		name := t.FullName
+		if len(name) == 0 {
+			name = t.Name
+		}
+		writeDOTNode(&buf, LineageKey(LINEAGE_TABLE, t.ID), "box", name, "")
+	}
+	for _, ds := range g.Datasources {
+		writeDOTNode(&buf, LineageKey(LINEAGE_DATASOURCE, ds.ID), "component", ds.Name, contentDetail(ds.Project, ds.Owner))
+	}
+	for _, wb := range g.Workbooks {
+		writeDOTNode(&buf, LineageKey(LINEAGE_WORKBOOK, wb.ID), "folder", wb.Name, contentDetail(wb.Project, wb.Owner))
+	}
+	for _, v := range g.Views {
+		writeDOTNode(&buf, LineageKey(LINEAGE_VIEW, v.ID), "note", v.Name, "")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&buf, "  %q -> %q;\n", e.From, e.To)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func writeDOTNode(buf *bytes.Buffer, key, shape, name, detail string) {
+	label := name
+	if len(detail) > 0 {
+		label += "\n" + detail
+	}
+	fmt.Fprintf(buf, "  %q [shape=%s, label=%q];\n", key, shape, label)
+}
+
+func contentDetail(project *Project, owner *User) string {
+	details := []string{}
+	if project != nil {
+		details = append(details, "project: "+project.Name)
+	}
+	if owner != nil {
+		details = append(details, "owner: "+owner.Name)
+	}
+	return strings.Join(details, "\n")
+}
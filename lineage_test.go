@@ -0,0 +1,126 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+// table_lineage.json has orders in two schemas of warehouse, both read by the
+// published Sales datasource and the Executive workbook on top of it, orders in
+// public also read by Operations through an embedded connection, and orders in
+// the archive database that TableImpact("warehouse", ...) leaves out.
+const tableLineageDOT = `digraph lineage {
+  rankdir=LR;
+  "database:db-1" [shape=cylinder, label="warehouse\npostgres"];
+  "table:t-public" [shape=box, label="[public].[orders]"];
+  "table:t-staging" [shape=box, label="[staging].[orders]"];
+  "datasource:ds-1" [shape=component, label="Sales\nproject: Finance\nowner: ann"];
+  "workbook:wb-1" [shape=folder, label="Executive\nproject: Finance\nowner: ann"];
+  "workbook:wb-2" [shape=folder, label="Operations\nproject: Ops\nowner: bo"];
+  "view:v-1" [shape=note, label="Revenue"];
+  "view:v-2" [shape=note, label="Overview"];
+  "view:m-hidden" [shape=note, label="Scratch"];
+  "database:db-1" -> "table:t-public";
+  "table:t-public" -> "datasource:ds-1";
+  "datasource:ds-1" -> "workbook:wb-1";
+  "workbook:wb-1" -> "view:v-1";
+  "workbook:wb-1" -> "view:v-2";
+  "table:t-public" -> "workbook:wb-2";
+  "workbook:wb-2" -> "view:m-hidden";
+  "database:db-1" -> "table:t-staging";
+  "table:t-staging" -> "datasource:ds-1";
+}
+`
+
+func tableImpact(t *testing.T) *tableau4go.LineageGraph {
+	t.Helper()
+	body, err := ioutil.ReadFile("testdata/metadata/table_lineage.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := metadataServer(t, func(request graphQLRequest) (int, string) {
+		if request.Query != tableau4go.TABLE_LINEAGE_QUERY || request.Variables["name"] != "orders" {
+			t.Errorf("request = %+v", request)
+		}
+		return http.StatusOK, string(body)
+	})
+	graph, err := api.TableImpact("warehouse", "", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return graph
+}
+
+func TestLineageGraph(t *testing.T) {
+	graph := tableImpact(t)
+	key := tableau4go.LineageKey
+	wantEdges := []tableau4go.LineageEdge{
+		{From: key(tableau4go.LINEAGE_DATABASE, "db-1"), To: key(tableau4go.LINEAGE_TABLE, "t-public")},
+		{From: key(tableau4go.LINEAGE_TABLE, "t-public"), To: key(tableau4go.LINEAGE_DATASOURCE, "ds-1")},
+		{From: key(tableau4go.LINEAGE_DATASOURCE, "ds-1"), To: key(tableau4go.LINEAGE_WORKBOOK, "wb-1")},
+		{From: key(tableau4go.LINEAGE_WORKBOOK, "wb-1"), To: key(tableau4go.LINEAGE_VIEW, "v-1")},
+		{From: key(tableau4go.LINEAGE_WORKBOOK, "wb-1"), To: key(tableau4go.LINEAGE_VIEW, "v-2")},
+		// the embedded connection leaves no datasource in between
+		{From: key(tableau4go.LINEAGE_TABLE, "t-public"), To: key(tableau4go.LINEAGE_WORKBOOK, "wb-2")},
+		// a hidden sheet has no luid and goes by its Metadata API id
+		{From: key(tableau4go.LINEAGE_WORKBOOK, "wb-2"), To: key(tableau4go.LINEAGE_VIEW, "m-hidden")},
+		{From: key(tableau4go.LINEAGE_DATABASE, "db-1"), To: key(tableau4go.LINEAGE_TABLE, "t-staging")},
+		{From: key(tableau4go.LINEAGE_TABLE, "t-staging"), To: key(tableau4go.LINEAGE_DATASOURCE, "ds-1")},
+	}
+	if !reflect.DeepEqual(graph.Edges, wantEdges) {
+		t.Errorf("edges =\n%v\nwant\n%v", graph.Edges, wantEdges)
+	}
+	// Sales and Executive hang off both tables but show up once, the embedded
+	// datasource not at all
+	if len(graph.Databases) != 1 || len(graph.Tables) != 2 || len(graph.Datasources) != 1 || len(graph.Workbooks) != 2 || len(graph.Views) != 3 {
+		t.Errorf("%d databases, %d tables, %d datasources, %d workbooks, %d views",
+			len(graph.Databases), len(graph.Tables), len(graph.Datasources), len(graph.Workbooks), len(graph.Views))
+	}
+	if ds := graph.Datasources[0]; ds.ID != "ds-1" || !ds.IsCertified || ds.Owner == nil || ds.Owner.ID != "u-1" || ds.Project == nil || ds.Project.Name != "Finance" {
+		t.Errorf("datasource = %+v", ds)
+	}
+	if len(graph.Owners) != 2 || len(graph.Projects) != 2 {
+		t.Errorf("owners %+v, projects %+v", graph.Owners, graph.Projects)
+	}
+}
+
+func TestLineageGraphExport(t *testing.T) {
+	graph := tableImpact(t)
+	if dot := graph.DOT(); dot != tableLineageDOT {
+		t.Errorf("DOT() =\n%s\nwant\n%s", dot, tableLineageDOT)
+	}
+	content, err := graph.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile("testdata/metadata/table_lineage_graph.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != strings.TrimSpace(string(want)) {
+		t.Errorf("JSON() =\n%s\nwant testdata/metadata/table_lineage_graph.json", content)
+	}
+}
+
+func TestLineageGraphEmpty(t *testing.T) {
+	graph := tableau4go.NewLineageGraph(nil)
+	if dot := graph.DOT(); dot != "digraph lineage {\n  rankdir=LR;\n}\n" {
+		t.Errorf("DOT() = %q", dot)
+	}
+}
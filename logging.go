@@ -0,0 +1,111 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// secrets that show up in request and response bodies: sign in passwords,
+// connection credentials, personal access token secrets and the issued token.
+// A json string runs to the first quote that is not escaped, an xml attribute
+// cannot hold a bare quote at all.
+var body_secrets = []*regexp.Regexp{
+	regexp.MustCompile(`((?:password|personalAccessTokenSecret|token)=")[^"]*(")`),
+	regexp.MustCompile(`("(?:password|personalAccessTokenSecret|token)"\s*:\s*")(?:[^"\\]|\\.)*(")`),
+}
+
+func redactBody(body []byte) string {
+	s := string(body)
+	for _, secret := range body_secrets {
+		s = secret.ReplaceAllString(s, "${1}"+redacted+"${2}")
+	}
+	return s
+}
+
+// Redact blanks out the same secrets as the body logging, for code that keeps
+// traffic around, like the tableautest cassettes.
+func Redact(body []byte) []byte {
+	return []byte(redactBody(body))
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name := range header {
+		if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(auth_header) {
+			headers[name] = redacted
+		} else {
+			headers[name] = header.Get(name)
+		}
+	}
+	return headers
+}
+
+// requestID is the id Tableau Server stamps on a response, quote it to support.
+func requestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	if id := resp.Header.Get("X-Request-Id"); len(id) > 0 {
+		return id
+	}
+	return resp.Header.Get("Request-Id")
+}
+
+// logRequest records one HTTP attempt at info level, or warn when it failed.
+func (api *API) logRequest(req *http.Request, resp *http.Response, err error, attempt int, duration time.Duration) {
+	if api.Logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Duration("duration", duration),
+	}
+	if attempt > 1 {
+		attrs = append(attrs, slog.Int("attempt", attempt))
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+		if id := requestID(resp); len(id) > 0 {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if resp.StatusCode >= 500 {
+			level = slog.LevelWarn
+		}
+	}
+	api.Logger.LogAttrs(req.Context(), level, "tableau request", attrs...)
+}
+
+// logBody dumps a request or response body at debug level when LogBodies is set.
+func (api *API) logBody(ctx context.Context, message string, method string, requestUrl string, header http.Header, body []byte) {
+	if api.Logger == nil || !api.LogBodies || !api.Logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("url", requestUrl),
+		slog.Any("headers", redactHeaders(header)),
+		slog.String("body", redactBody(body)),
+	}
+	api.Logger.LogAttrs(ctx, slog.LevelDebug, message, attrs...)
+}
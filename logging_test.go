@@ -0,0 +1,129 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		name   string
+		body   string
+		secret string
+	}{
+		{"xml credentials", `<tsRequest><credentials name="admin" password="hunter2"><site contentUrl=""/></credentials></tsRequest>`, "hunter2"},
+		{"xml connection credentials", `<connectionCredentials name="dbuser" password="dbpass" embed="true"/>`, "dbpass"},
+		{"xml token", `<tsResponse><credentials token="tok-123"><site id="s"/></credentials></tsResponse>`, "tok-123"},
+		{"xml access token secret", `<credentials personalAccessTokenName="ci" personalAccessTokenSecret="pat-456"/>`, "pat-456"},
+		{"json credentials", `{"credentials":{"name":"admin","password":"hunter2","site":{"contentUrl":""}}}`, "hunter2"},
+		{"json token", `{"credentials":{"token" : "tok-123","site":{"id":"s"}}}`, "tok-123"},
+		{"json access token secret", `{"credentials":{"personalAccessTokenName":"ci","personalAccessTokenSecret":"pat-456"}}`, "pat-456"},
+		{"json password with a quote", `{"credentials":{"name":"admin","password":"ab\"cdSECRET","site":{}}}`, "SECRET"},
+		{"json password ending in a backslash", `{"credentials":{"password":"abSECRET\\","name":"admin"}}`, "SECRET"},
+	}
+	for _, c := range cases {
+		got := string(Redact([]byte(c.body)))
+		if strings.Contains(got, c.secret) || !strings.Contains(got, redacted) {
+			t.Errorf("%s: Redact() = %s", c.name, got)
+		}
+		if strings.HasPrefix(c.body, "{") && !json.Valid([]byte(got)) {
+			t.Errorf("%s: Redact() broke the json: %s", c.name, got)
+		}
+	}
+	if body := `{"name":"admin","site":{"contentUrl":"finance"}}`; string(Redact([]byte(body))) != body {
+		t.Errorf("Redact changed a body without secrets: %s", Redact([]byte(body)))
+	}
+}
+
+// recordingHandler keeps the records and the contexts they were logged with.
+type recordingHandler struct {
+	records  []slog.Record
+	contexts []context.Context
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordingHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.records = append(h.records, record)
+	h.contexts = append(h.contexts, ctx)
+	return nil
+}
+
+type logKey struct{}
+
+func TestLogRequestUsesRequestContext(t *testing.T) {
+	handler := &recordingHandler{}
+	api := &API{Logger: slog.New(handler)}
+	ctx := context.WithValue(context.Background(), logKey{}, "call-1")
+	req, _ := http.NewRequestWithContext(ctx, GET, "http://tableau.example/api/3.21/serverinfo", nil)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Request-Id": {"req-7"}}}
+	api.logRequest(req, resp, nil, 2, 0)
+	if len(handler.records) != 1 {
+		t.Fatalf("%d records, want 1", len(handler.records))
+	}
+	if handler.contexts[0].Value(logKey{}) != "call-1" {
+		t.Error("logRequest did not log with the request's context")
+	}
+	attrs := map[string]string{}
+	handler.records[0].Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	if attrs["status"] != "200" || attrs["attempt"] != "2" || attrs["request_id"] != "req-7" {
+		t.Errorf("attrs = %v", attrs)
+	}
+}
+
+// TestLogBodies signs in over json with a password holding a quote and checks
+// the debug output for the password, the issued token and the auth header.
+func TestLogBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(content_type_header, application_json_content_type)
+		if strings.HasSuffix(r.URL.Path, "/signin") {
+			w.Write([]byte(`{"credentials":{"token":"tok-SECRET","site":{"id":"site-1","contentUrl":""},"user":{"id":"user-1"}}}`))
+			return
+		}
+		w.Write([]byte(`{"projects":{"project":[]}}`))
+	}))
+	defer server.Close()
+	var out bytes.Buffer
+	api := NewAPI(server.URL, "3.21", "", "", false)
+	api.Format = FORMAT_JSON
+	api.Logger = slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	api.LogBodies = true
+	if err := api.Signin("admin", `ab"cdSECRET`, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.QueryProjects(api.SiteID()); err != nil {
+		t.Fatal(err)
+	}
+	logged := out.String()
+	if strings.Contains(logged, "SECRET") {
+		t.Errorf("secrets logged:\n%s", logged)
+	}
+	if !strings.Contains(logged, `"X-Tableau-Auth":"`+redacted+`"`) {
+		t.Errorf("X-Tableau-Auth not logged as %s:\n%s", redacted, logged)
+	}
+	if strings.Count(logged, `"msg":"tableau request"`) != 2 {
+		t.Errorf("want a line per request:\n%s", logged)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// page size used by MetadataQueryAll when the caller does not set $first
+const metadata_page_size = 100
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e GraphQLError) Error() string {
+	if len(e.Path) > 0 {
+		return fmt.Sprintf("GraphQL: %s (path %v)", e.Message, e.Path)
+	}
+	return fmt.Sprintf("GraphQL: %s", e.Message)
+}
+
+// GraphQLErrors is the errors array of a Metadata API response. The server can
+// return partial data alongside errors, in which case the data is still decoded.
+type GraphQLErrors []GraphQLError
+
+func (errs GraphQLErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors,omitempty"`
+}
+
+// PageInfo is selected on *Connection fields to page through them with MetadataQueryAll.
+type PageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// MetadataQueryRaw posts a query to the Metadata API and returns the "data" object as is.
+// https://help.tableau.com/current/api/metadata_api/en-us/index.html
+func (api *API) MetadataQueryRaw(query string, variables map[string]interface{}) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s/api/metadata/graphql", api.Server)
+	statusCode, body, err := api.makeJSONRequest(url, POST, graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return nil, err
+	}
+	retval := graphQLResponse{}
+	if jsonErr := json.Unmarshal(body, &retval); jsonErr != nil {
+		if statusCode >= 300 {
+			return nil, responseError(POST, url, statusCode, body)
+		}
+		return nil, decodeError(POST, url, body, jsonErr)
+	}
+	if len(retval.Errors) > 0 {
+		return retval.Data, retval.Errors
+	}
+	if statusCode >= 300 {
+		return nil, responseError(POST, url, statusCode, body)
+	}
+	return retval.Data, nil
+}
+
+// MetadataQuery posts a query and decodes the "data" object into result.
+func (api *API) MetadataQuery(query string, variables map[string]interface{}, result interface{}) error {
+	data, err := api.MetadataQueryRaw(query, variables)
+	if len(data) > 0 && string(data) != "null" && result != nil {
+		if jsonErr := json.Unmarshal(data, result); jsonErr != nil && err == nil {
+			err = jsonErr
+		}
+	}
+	return err
+}
+
+// MetadataQueryAll pages through a top level connection field, e.g.
+// databaseTablesConnection. The query must declare $first: Int and $after: String,
+// pass them to the connection, and select nodes and pageInfo { hasNextPage endCursor }.
+// fn is called with the raw nodes array of each page.
+func (api *API) MetadataQueryAll(query string, variables map[string]interface{}, connection string, fn func(nodes json.RawMessage) error) error {
+	vars := make(map[string]interface{}, len(variables)+2)
+	for k, v := range variables {
+		vars[k] = v
+	}
+	if _, ok := vars["first"]; !ok {
+		vars["first"] = metadata_page_size
+	}
+	for {
+		page := map[string]struct {
+			Nodes    json.RawMessage `json:"nodes"`
+			PageInfo PageInfo        `json:"pageInfo"`
+		}{}
+		if err := api.MetadataQuery(query, vars, &page); err != nil {
+			return err
+		}
+		conn, ok := page[connection]
+		if !ok {
+			return fmt.Errorf("Connection '%s' not found in Metadata API response", connection)
+		}
+		if err := fn(conn.Nodes); err != nil {
+			return err
+		}
+		if !conn.PageInfo.HasNextPage || len(conn.PageInfo.EndCursor) == 0 {
+			return nil
+		}
+		vars["after"] = conn.PageInfo.EndCursor
+	}
+}
+
+type MetadataUser struct {
+	Luid     string `json:"luid"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+type MetadataDatabase struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	ConnectionType string `json:"connectionType"`
+}
+
+type MetadataView struct {
+	ID   string `json:"id"`
+	Luid string `json:"luid"`
+	Name string `json:"name"`
+}
+
+type MetadataTable struct {
+	ID                    string               `json:"id"`
+	Name                  string               `json:"name"`
+	Schema                string               `json:"schema"`
+	FullName              string               `json:"fullName"`
+	Database              *MetadataDatabase    `json:"database"`
+	DownstreamDatasources []MetadataDatasource `json:"downstreamDatasources"`
+	DownstreamWorkbooks   []MetadataWorkbook   `json:"downstreamWorkbooks"`
+}
+
+type MetadataDatasource struct {
+	ID                  string             `json:"id"`
+	Luid                string             `json:"luid"`
+	Name                string             `json:"name"`
+	ProjectName         string             `json:"projectName"`
+	IsCertified         bool               `json:"isCertified"`
+	Owner               *MetadataUser      `json:"owner"`
+	UpstreamTables      []MetadataTable    `json:"upstreamTables"`
+	DownstreamWorkbooks []MetadataWorkbook `json:"downstreamWorkbooks"`
+}
+
+type MetadataWorkbook struct {
+	ID                  string               `json:"id"`
+	Luid                string               `json:"luid"`
+	Name                string               `json:"name"`
+	ProjectName         string               `json:"projectName"`
+	Owner               *MetadataUser        `json:"owner"`
+	UpstreamDatasources []MetadataDatasource `json:"upstreamDatasources"`
+	UpstreamTables      []MetadataTable      `json:"upstreamTables"`
+	Sheets              []MetadataView       `json:"sheets"`
+	Dashboards          []MetadataView       `json:"dashboards"`
+}
+
+// DATASOURCE_LINEAGE_QUERY takes $luid, the REST id of a published datasource.
+const DATASOURCE_LINEAGE_QUERY = `query datasourceLineage($luid: String!) {
+  publishedDatasources(filter: {luid: $luid}) {
+    id luid name projectName isCertified
+    owner { luid name username }
+    upstreamTables { id name schema fullName database { id name connectionType } }
+    downstreamWorkbooks {
+      id luid name projectName
+      owner { luid name username }
+      sheets { id luid name }
+      dashboards { id luid name }
+    }
+  }
+}`
+
+// WORKBOOK_LINEAGE_QUERY takes $luid, the REST id of a workbook.
+const WORKBOOK_LINEAGE_QUERY = `query workbookLineage($luid: String!) {
+  workbooks(filter: {luid: $luid}) {
+    id luid name projectName
+    owner { luid name username }
+    upstreamDatasources { id luid name projectName isCertified owner { luid name username } }
+    upstreamTables { id name schema fullName database { id name connectionType } }
+    sheets { id luid name }
+    dashboards { id luid name }
+  }
+}`
+
+// TABLE_LINEAGE_QUERY takes $name and $schema and pages with $first and $after.
+const TABLE_LINEAGE_QUERY = `query tableLineage($name: String!, $schema: String, $first: Int, $after: String) {
+  databaseTablesConnection(filter: {name: $name, schema: $schema}, first: $first, after: $after) {
+    nodes {
+      id name schema fullName
+      database { id name connectionType }
+      downstreamDatasources {
+        id luid name projectName isCertified
+        owner { luid name username }
+      }
+      downstreamWorkbooks {
+        id luid name projectName
+        owner { luid name username }
+        upstreamDatasources { id luid name }
+        sheets { id luid name }
+        dashboards { id luid name }
+      }
+    }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+func (api *API) QueryDatasourceLineage(datasourceId string) (MetadataDatasource, error) {
+	retval := struct {
+		PublishedDatasources []MetadataDatasource `json:"publishedDatasources"`
+	}{}
+	err := api.MetadataQuery(DATASOURCE_LINEAGE_QUERY, map[string]interface{}{"luid": datasourceId}, &retval)
+	if err != nil {
+		return MetadataDatasource{}, err
+	}
+	if len(retval.PublishedDatasources) == 0 {
+		return MetadataDatasource{}, ErrDoesNotExist
+	}
+	return retval.PublishedDatasources[0], nil
+}
+
+func (api *API) QueryWorkbookLineage(workbookId string) (MetadataWorkbook, error) {
+	retval := struct {
+		Workbooks []MetadataWorkbook `json:"workbooks"`
+	}{}
+	err := api.MetadataQuery(WORKBOOK_LINEAGE_QUERY, map[string]interface{}{"luid": workbookId}, &retval)
+	if err != nil {
+		return MetadataWorkbook{}, err
+	}
+	if len(retval.Workbooks) == 0 {
+		return MetadataWorkbook{}, ErrDoesNotExist
+	}
+	return retval.Workbooks[0], nil
+}
+
+// QueryTableLineage returns every table with the given name, and schema when not
+// blank, along with what reads from it. database narrows by database name,
+// blank matches any database.
+func (api *API) QueryTableLineage(database, schema, table string) ([]MetadataTable, error) {
+	variables := map[string]interface{}{"name": table}
+	if len(schema) > 0 {
+		variables["schema"] = schema
+	}
+	tables := []MetadataTable{}
+	err := api.MetadataQueryAll(TABLE_LINEAGE_QUERY, variables, "databaseTablesConnection", func(nodes json.RawMessage) error {
+		page := []MetadataTable{}
+		if err := json.Unmarshal(nodes, &page); err != nil {
+			return err
+		}
+		for _, t := range page {
+			if len(database) > 0 && (t.Database == nil || !strings.EqualFold(t.Database.Name, database)) {
+				continue
+			}
+			tables = append(tables, t)
+		}
+		return nil
+	})
+	return tables, err
+}
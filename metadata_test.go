@@ -0,0 +1,165 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// metadataServer answers /api/metadata/graphql with whatever respond returns
+// for each request.
+func metadataServer(t *testing.T, respond func(request graphQLRequest) (int, string)) *tableau4go.API {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/metadata/graphql" {
+			t.Errorf("%s %s, want POST /api/metadata/graphql", r.Method, r.URL.Path)
+		}
+		request := graphQLRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		statusCode, body := respond(request)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return tableau4go.NewAPI(server.URL, "3.21", "", "", false)
+}
+
+func TestMetadataPartialData(t *testing.T) {
+	data := `{"workbooks":[{"name":"Sales"},null]}`
+	api := metadataServer(t, func(graphQLRequest) (int, string) {
+		return http.StatusOK, `{"data":` + data + `,"errors":[{"message":"Permission denied","path":["workbooks",1],"locations":[{"line":1,"column":3}]}]}`
+	})
+	raw, err := api.MetadataQueryRaw(`{ workbooks { name } }`, nil)
+	if string(raw) != data {
+		t.Errorf("data = %s, want the partial data %s", raw, data)
+	}
+	var graphQLErrs tableau4go.GraphQLErrors
+	if !errors.As(err, &graphQLErrs) || len(graphQLErrs) != 1 || graphQLErrs[0].Message != "Permission denied" || graphQLErrs[0].Locations[0].Line != 1 {
+		t.Fatalf("err = %#v, want the GraphQL errors", err)
+	}
+	if want := "GraphQL: Permission denied (path [workbooks 1])"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	// MetadataQuery decodes what there is and still reports the errors
+	result := struct {
+		Workbooks []*tableau4go.MetadataWorkbook `json:"workbooks"`
+	}{}
+	err = api.MetadataQuery(`{ workbooks { name } }`, nil, &result)
+	if !errors.As(err, &graphQLErrs) {
+		t.Errorf("MetadataQuery err = %v", err)
+	}
+	if len(result.Workbooks) != 2 || result.Workbooks[0].Name != "Sales" || result.Workbooks[1] != nil {
+		t.Errorf("MetadataQuery decoded %+v", result.Workbooks)
+	}
+}
+
+func TestMetadataErrorStatus(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		graphQL  bool
+		sentinel error
+	}{
+		{"graphql errors on a 400", http.StatusBadRequest, `{"errors":[{"message":"Syntax Error"}]}`, true, nil},
+		{"unauthorized", http.StatusUnauthorized, `{"error":"expired"}`, false, tableau4go.ErrUnauthorized},
+		{"html from a proxy", http.StatusBadGateway, `<html>Bad Gateway</html>`, false, nil},
+	}
+	for _, c := range cases {
+		api := metadataServer(t, func(graphQLRequest) (int, string) { return c.status, c.body })
+		raw, err := api.MetadataQueryRaw(`{ workbooks { name } }`, nil)
+		if len(raw) > 0 && string(raw) != "null" {
+			t.Errorf("%s: data = %s", c.name, raw)
+		}
+		var graphQLErrs tableau4go.GraphQLErrors
+		var apiErr *tableau4go.APIError
+		switch {
+		case c.graphQL && !errors.As(err, &graphQLErrs):
+			t.Errorf("%s: err = %v, want GraphQLErrors", c.name, err)
+		case !c.graphQL && (!errors.As(err, &apiErr) || apiErr.StatusCode != c.status):
+			t.Errorf("%s: err = %v, want an APIError with status %d", c.name, err, c.status)
+		case c.sentinel != nil && !errors.Is(err, c.sentinel):
+			t.Errorf("%s: err = %v, want %v", c.name, err, c.sentinel)
+		}
+	}
+}
+
+func TestMetadataQueryAllFollowsCursors(t *testing.T) {
+	var afters []interface{}
+	api := metadataServer(t, func(request graphQLRequest) (int, string) {
+		if request.Variables["first"] != float64(100) || request.Variables["name"] != "orders" {
+			t.Errorf("variables = %v", request.Variables)
+		}
+		after := request.Variables["after"]
+		afters = append(afters, after)
+		page, next := 1, "true"
+		switch after {
+		case "cursor-1":
+			page = 2
+		case "cursor-2":
+			page, next = 3, "false"
+		}
+		return http.StatusOK, fmt.Sprintf(`{"data":{"databaseTablesConnection":{
+			"nodes":[{"id":"t%d","name":"orders"}],
+			"pageInfo":{"hasNextPage":%s,"endCursor":"cursor-%d"}}}}`, page, next, page)
+	})
+	var ids []string
+	err := api.MetadataQueryAll(tableau4go.TABLE_LINEAGE_QUERY, map[string]interface{}{"name": "orders"}, "databaseTablesConnection", func(nodes json.RawMessage) error {
+		page := []tableau4go.MetadataTable{}
+		if err := json.Unmarshal(nodes, &page); err != nil {
+			return err
+		}
+		for _, table := range page {
+			ids = append(ids, table.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "t1,t2,t3" {
+		t.Errorf("nodes = %v, want the three pages in order", ids)
+	}
+	if len(afters) != 3 || afters[0] != nil || afters[1] != "cursor-1" || afters[2] != "cursor-2" {
+		t.Errorf("after = %v, want none then each endCursor", afters)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = api.MetadataQueryAll(tableau4go.TABLE_LINEAGE_QUERY, map[string]interface{}{"name": "orders"}, "databaseTablesConnection", func(json.RawMessage) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("fn's error: err = %v after %d pages, want it back after 1", err, calls)
+	}
+	err = api.MetadataQueryAll(tableau4go.TABLE_LINEAGE_QUERY, map[string]interface{}{"name": "orders"}, "tablesConnection", func(json.RawMessage) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "tablesConnection") {
+		t.Errorf("unknown connection: err = %v", err)
+	}
+}
@@ -12,9 +12,14 @@
 package tableau4go
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
+	"log/slog"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 const API_VERSION = "2.0"
@@ -22,26 +27,51 @@ const DEFAULT_SERVER = "http://localhost:8000"
 const BOUNDARY_STRING = "813e3160-3c95-11e5-a151-feff819cdc9f"
 const CRLF = "\r\n"
 
+// API is safe for concurrent use once configured: set the exported fields before
+// sharing it between goroutines and leave them alone afterwards. The session (auth
+// token, site and user ids) is guarded and changed only through Signin, SwitchSite,
+// Signout and SetAuthToken. An API must not be copied, use Clone to get one with
+// its own session, for example to switch a copy into another site while the
+// original stays on its site, or WithContext for one sharing the session.
 type API struct {
 	Server              string
 	Version             string
 	Boundary            string
-	AuthToken           string
 	OmitDefaultSiteName bool
 	DefaultSiteName     string
-}
-
-func DefaultApi() API {
+	// RetryPolicy is nil by default, meaning every call is tried once
+	RetryPolicy *RetryPolicy
+	// Limiter is nil by default, meaning calls are not throttled client side
+	Limiter *Limiter
+	// Logger gets a line per request when set. Auth tokens, passwords and
+	// personal access token secrets are redacted.
+	Logger *slog.Logger
+	// LogBodies adds request and response bodies to Logger at debug level
+	LogBodies bool
+	// Observer is told about every call when set, see the tableauotel package
+	Observer Observer
+	// HTTPClient makes the calls when set, DefaultTimeoutClient otherwise. Give it
+	// a tableautest.Cassette as Transport to record or replay traffic.
+	HTTPClient *http.Client
+	// Format is the wire format of the REST calls, FORMAT_XML (the default when
+	// empty) or FORMAT_JSON
+	Format      string
+	ctx         context.Context
+	session     *session
+	sessionOnce sync.Once
+}
+
+func DefaultApi() *API {
 	api := NewAPI(DEFAULT_SERVER, API_VERSION, BOUNDARY_STRING, "Default", true)
 	return api
 }
 
-func NewAPI(server string, version string, boundary string, defaultSiteName string, omitDefaultSiteName bool) API {
+func NewAPI(server string, version string, boundary string, defaultSiteName string, omitDefaultSiteName bool) *API {
 	fixedUpServer := server
 	if strings.HasSuffix(server, "/") {
 		fixedUpServer = server[0 : len(server)-1]
 	}
-	return API{Server: fixedUpServer, Version: version, Boundary: boundary, DefaultSiteName: defaultSiteName, OmitDefaultSiteName: omitDefaultSiteName}
+	return &API{Server: fixedUpServer, Version: version, Boundary: boundary, DefaultSiteName: defaultSiteName, OmitDefaultSiteName: omitDefaultSiteName, session: &session{}}
 }
 
 type Project struct {
@@ -94,9 +124,43 @@ type Datasource struct {
 	ID                    string                 `json:"id,omitempty" xml:"id,attr,omitempty"`
 	Name                  string                 `json:"name,omitempty" xml:"name,attr,omitempty"`
 	Type                  string                 `json:"type,omitempty" xml:"type,attr,omitempty"`
+	ContentUrl            string                 `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	Description           string                 `json:"description,omitempty" xml:"description,attr,omitempty"`
+	WebpageUrl            string                 `json:"webpageUrl,omitempty" xml:"webpageUrl,attr,omitempty"`
+	CreatedAt             *time.Time             `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt             *time.Time             `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	IsCertified           bool                   `json:"isCertified,omitempty" xml:"isCertified,attr,omitempty"`
+	CertificationNote     string                 `json:"certificationNote,omitempty" xml:"certificationNote,attr,omitempty"`
+	EncryptExtracts       bool                   `json:"encryptExtracts,omitempty" xml:"encryptExtracts,attr,omitempty"`
+	HasExtracts           bool                   `json:"hasExtracts,omitempty" xml:"hasExtracts,attr,omitempty"`
+	Size                  int64                  `json:"size,string,omitempty" xml:"size,attr,omitempty"`
 	ConnectionCredentials *ConnectionCredentials `json:"connectionCredentials,omitempty" xml:"connectionCredentials,omitempty"`
 	Project               *Project               `json:"project,omitempty" xml:"project,omitempty"`
 	Owner                 *User                  `json:"owner,omitempty" xml:"owner,omitempty"`
+	Tags                  *Tags                  `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+// DatasourceCertification is sent on its own rather than as a Datasource because
+// isCertified="false" has to reach the server to uncertify, and Datasource omits it.
+type DatasourceCertification struct {
+	IsCertified       bool   `json:"isCertified" xml:"isCertified,attr"`
+	CertificationNote string `json:"certificationNote,omitempty" xml:"certificationNote,attr,omitempty"`
+}
+
+type UpdateDatasourceCertificationRequest struct {
+	Request DatasourceCertification `json:"datasource,omitempty" xml:"datasource,omitempty"`
+}
+
+func (req UpdateDatasourceCertificationRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateDatasourceCertificationRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateDatasourceCertificationRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type UpdateDatasourceResponse struct {
+	Datasource Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
 }
 
 type Datasources struct {
@@ -111,6 +175,433 @@ func (ds *Datasource) XML() ([]byte, error) {
 	return xml.MarshalIndent(ds, "", "   ")
 }
 
+type Workbook struct {
+	ID         string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name       string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	ShowTabs   bool       `json:"showTabs,omitempty" xml:"showTabs,attr,omitempty"`
+	Size       int64      `json:"size,string,omitempty" xml:"size,attr,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Project    *Project   `json:"project,omitempty" xml:"project,omitempty"`
+	Owner      *User      `json:"owner,omitempty" xml:"owner,omitempty"`
+	Views      *Views     `json:"views,omitempty" xml:"views,omitempty"`
+	Tags       *Tags      `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+type View struct {
+	ID         string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name       string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Workbook   *Workbook  `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	Owner      *User      `json:"owner,omitempty" xml:"owner,omitempty"`
+	Project    *Project   `json:"project,omitempty" xml:"project,omitempty"`
+	Usage      *ViewUsage `json:"usage,omitempty" xml:"usage,omitempty"`
+	Tags       *Tags      `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+type ViewUsage struct {
+	TotalViewCount int `json:"totalViewCount,string" xml:"totalViewCount,attr"`
+}
+
+type Views struct {
+	Views []View `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+type QueryViewsResponse struct {
+	Views Views `json:"views,omitempty" xml:"views,omitempty"`
+}
+
+type QueryViewResponse struct {
+	View View `json:"view,omitempty" xml:"view,omitempty"`
+}
+
+type Tag struct {
+	Label string `json:"label,omitempty" xml:"label,attr,omitempty"`
+}
+
+type Tags struct {
+	Tags []Tag `json:"tag,omitempty" xml:"tag,omitempty"`
+}
+
+// Labels returns the tag labels, it is safe to call on a nil *Tags.
+func (t *Tags) Labels() []string {
+	if t == nil {
+		return nil
+	}
+	labels := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		labels = append(labels, tag.Label)
+	}
+	return labels
+}
+
+func NewTags(labels ...string) Tags {
+	tags := Tags{}
+	for _, label := range labels {
+		tags.Tags = append(tags.Tags, Tag{Label: label})
+	}
+	return tags
+}
+
+type AddTagsRequest struct {
+	Request Tags `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+func (req AddTagsRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddTagsRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddTagsRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type AddTagsResponse struct {
+	Tags Tags `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+type Flow struct {
+	ID          string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name        string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Description string     `json:"description,omitempty" xml:"description,attr,omitempty"`
+	WebpageUrl  string     `json:"webpageUrl,omitempty" xml:"webpageUrl,attr,omitempty"`
+	FileType    string     `json:"fileType,omitempty" xml:"fileType,attr,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Project     *Project   `json:"project,omitempty" xml:"project,omitempty"`
+	Owner       *User      `json:"owner,omitempty" xml:"owner,omitempty"`
+	Tags        *Tags      `json:"tags,omitempty" xml:"tags,omitempty"`
+}
+
+type Flows struct {
+	Flows []Flow `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+type QueryFlowsResponse struct {
+	Flows Flows `json:"flows,omitempty" xml:"flows,omitempty"`
+}
+
+type FlowOutputStep struct {
+	ID   string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,attr,omitempty"`
+}
+
+type FlowOutputSteps struct {
+	FlowOutputSteps []FlowOutputStep `json:"flowOutputStep,omitempty" xml:"flowOutputStep,omitempty"`
+}
+
+type FlowResponse struct {
+	Flow            Flow            `json:"flow,omitempty" xml:"flow,omitempty"`
+	FlowOutputSteps FlowOutputSteps `json:"flowOutputSteps,omitempty" xml:"flowOutputSteps,omitempty"`
+}
+
+type FlowCreateRequest struct {
+	Request Flow `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+func (req FlowCreateRequest) XML() ([]byte, error) {
+	tmp := struct {
+		FlowCreateRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{FlowCreateRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type FlowRun struct {
+	ID              string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	FlowID          string     `json:"flowId,omitempty" xml:"flowId,attr,omitempty"`
+	Status          string     `json:"status,omitempty" xml:"status,attr,omitempty"`
+	Progress        int        `json:"progress,string,omitempty" xml:"progress,attr,omitempty"`
+	StartedAt       *time.Time `json:"startedAt,omitempty" xml:"startedAt,attr,omitempty"`
+	CompletedAt     *time.Time `json:"completedAt,omitempty" xml:"completedAt,attr,omitempty"`
+	BackgroundJobID string     `json:"backgroundJobId,omitempty" xml:"backgroundJobId,attr,omitempty"`
+}
+
+type FlowRuns struct {
+	FlowRuns []FlowRun `json:"flowRuns,omitempty" xml:"flowRuns,omitempty"`
+}
+
+type QueryFlowRunsResponse struct {
+	FlowRuns FlowRuns `json:"flowRuns,omitempty" xml:"flowRuns,omitempty"`
+}
+
+type FlowRunResponse struct {
+	FlowRun FlowRun `json:"flowRuns,omitempty" xml:"flowRuns,omitempty"`
+}
+
+// FlowRunTask is a scheduled run of a flow, as opposed to FlowRun which is one execution.
+type FlowRunTask struct {
+	ID       string    `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Priority int       `json:"priority,string,omitempty" xml:"priority,attr,omitempty"`
+	Type     string    `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Schedule *Schedule `json:"schedule,omitempty" xml:"schedule,omitempty"`
+	Flow     *Flow     `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+type FlowRunTaskWrapper struct {
+	FlowRun FlowRunTask `json:"flowRun,omitempty" xml:"flowRun,omitempty"`
+}
+
+type FlowRunTasks struct {
+	Tasks []FlowRunTaskWrapper `json:"task,omitempty" xml:"task,omitempty"`
+}
+
+type QueryFlowRunTasksResponse struct {
+	Tasks FlowRunTasks `json:"tasks,omitempty" xml:"tasks,omitempty"`
+}
+
+type FlowRunTaskResponse struct {
+	Task FlowRunTaskWrapper `json:"task,omitempty" xml:"task,omitempty"`
+}
+
+type Job struct {
+	ID          string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Mode        string     `json:"mode,omitempty" xml:"mode,attr,omitempty"`
+	Type        string     `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Progress    int        `json:"progress,string,omitempty" xml:"progress,attr,omitempty"`
+	FinishCode  *int       `json:"finishCode,string,omitempty" xml:"finishCode,attr,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty" xml:"startedAt,attr,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" xml:"completedAt,attr,omitempty"`
+	FlowRun     *FlowRun   `json:"flowRun,omitempty" xml:"flowRun,omitempty"`
+}
+
+// Finished reports whether the job has a finish code, 0 meaning success.
+func (j Job) Finished() bool {
+	return j.FinishCode != nil
+}
+
+func (j Job) Succeeded() bool {
+	return j.FinishCode != nil && *j.FinishCode == 0
+}
+
+type JobResponse struct {
+	Job Job `json:"job,omitempty" xml:"job,omitempty"`
+}
+
+// Favorite carries exactly one of its content fields, depending on what was favorited.
+type Favorite struct {
+	Label      string      `json:"label,omitempty" xml:"label,attr,omitempty"`
+	Workbook   *Workbook   `json:"workbook,omitempty" xml:"workbook,omitempty"`
+	View       *View       `json:"view,omitempty" xml:"view,omitempty"`
+	Datasource *Datasource `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	Project    *Project    `json:"project,omitempty" xml:"project,omitempty"`
+	Flow       *Flow       `json:"flow,omitempty" xml:"flow,omitempty"`
+}
+
+type Favorites struct {
+	Favorites []Favorite `json:"favorite,omitempty" xml:"favorite,omitempty"`
+}
+
+type AddFavoriteRequest struct {
+	Request Favorite `json:"favorite,omitempty" xml:"favorite,omitempty"`
+}
+
+func (req AddFavoriteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		AddFavoriteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{AddFavoriteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type FavoritesResponse struct {
+	Favorites Favorites `json:"favorites,omitempty" xml:"favorites,omitempty"`
+}
+
+const SUBSCRIPTION_CONTENT_VIEW = "View"
+const SUBSCRIPTION_CONTENT_WORKBOOK = "Workbook"
+
+type Schedule struct {
+	ID        string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name      string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	State     string     `json:"state,omitempty" xml:"state,attr,omitempty"`
+	Priority  int        `json:"priority,string,omitempty" xml:"priority,attr,omitempty"`
+	Type      string     `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Frequency string     `json:"frequency,omitempty" xml:"frequency,attr,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	NextRunAt *time.Time `json:"nextRunAt,omitempty" xml:"nextRunAt,attr,omitempty"`
+}
+
+type SubscriptionContent struct {
+	ID              string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Type            string `json:"type,omitempty" xml:"type,attr,omitempty"`
+	SendIfViewEmpty bool   `json:"sendIfViewEmpty" xml:"sendIfViewEmpty,attr"`
+}
+
+// Subscription is sent whole on update, so query it first and change the fields you need.
+type Subscription struct {
+	ID              string               `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Subject         string               `json:"subject,omitempty" xml:"subject,attr,omitempty"`
+	Message         string               `json:"message,omitempty" xml:"message,attr,omitempty"`
+	AttachImage     bool                 `json:"attachImage" xml:"attachImage,attr"`
+	AttachPdf       bool                 `json:"attachPdf" xml:"attachPdf,attr"`
+	PageOrientation string               `json:"pageOrientation,omitempty" xml:"pageOrientation,attr,omitempty"`
+	PageSizeOption  string               `json:"pageSizeOption,omitempty" xml:"pageSizeOption,attr,omitempty"`
+	Suspended       *bool                `json:"suspended,omitempty" xml:"suspended,attr,omitempty"`
+	Content         *SubscriptionContent `json:"content,omitempty" xml:"content,omitempty"`
+	Schedule        *Schedule            `json:"schedule,omitempty" xml:"schedule,omitempty"`
+	User            *User                `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+func NewSubscription(subject string, contentType string, contentId string, scheduleId string, userId string) Subscription {
+	return Subscription{
+		Subject:     subject,
+		AttachImage: true,
+		Content:     &SubscriptionContent{ID: contentId, Type: contentType},
+		Schedule:    &Schedule{ID: scheduleId},
+		User:        &User{ID: userId},
+	}
+}
+
+type Subscriptions struct {
+	Subscriptions []Subscription `json:"subscription,omitempty" xml:"subscription,omitempty"`
+}
+
+type SubscriptionRequest struct {
+	Request Subscription `json:"subscription,omitempty" xml:"subscription,omitempty"`
+}
+
+func (req SubscriptionRequest) XML() ([]byte, error) {
+	tmp := struct {
+		SubscriptionRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{SubscriptionRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type SubscriptionResponse struct {
+	Subscription Subscription `json:"subscription,omitempty" xml:"subscription,omitempty"`
+}
+
+type QuerySubscriptionsResponse struct {
+	Subscriptions Subscriptions `json:"subscriptions,omitempty" xml:"subscriptions,omitempty"`
+}
+
+type DataAlertRecipient struct {
+	ID       string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	LastSent *time.Time `json:"lastSent,omitempty" xml:"lastSent,attr,omitempty"`
+}
+
+type DataAlertRecipients struct {
+	Recipients []DataAlertRecipient `json:"recipient,omitempty" xml:"recipient,omitempty"`
+}
+
+type DataAlert struct {
+	ID         string               `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Subject    string               `json:"subject,omitempty" xml:"subject,attr,omitempty"`
+	CreatorID  string               `json:"creatorId,omitempty" xml:"creatorId,attr,omitempty"`
+	Frequency  string               `json:"frequency,omitempty" xml:"frequency,attr,omitempty"`
+	Public     bool                 `json:"public,omitempty" xml:"public,attr,omitempty"`
+	CreatedAt  *time.Time           `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt  *time.Time           `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Owner      *User                `json:"owner,omitempty" xml:"owner,omitempty"`
+	View       *View                `json:"view,omitempty" xml:"view,omitempty"`
+	Recipients *DataAlertRecipients `json:"recipients,omitempty" xml:"recipients,omitempty"`
+}
+
+type DataAlerts struct {
+	DataAlerts []DataAlert `json:"dataAlert,omitempty" xml:"dataAlert,omitempty"`
+}
+
+type QueryDataAlertsResponse struct {
+	DataAlerts DataAlerts `json:"dataAlerts,omitempty" xml:"dataAlerts,omitempty"`
+}
+
+type DataAlertResponse struct {
+	DataAlert DataAlert `json:"dataAlert,omitempty" xml:"dataAlert,omitempty"`
+}
+
+type UpdateDataAlertRequest struct {
+	Request DataAlert `json:"dataAlert,omitempty" xml:"dataAlert,omitempty"`
+}
+
+func (req UpdateDataAlertRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UpdateDataAlertRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UpdateDataAlertRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type UserRequest struct {
+	Request User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+func (req UserRequest) XML() ([]byte, error) {
+	tmp := struct {
+		UserRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{UserRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type WebhookDestinationHTTP struct {
+	Method string `json:"method,omitempty" xml:"method,attr,omitempty"`
+	Url    string `json:"url,omitempty" xml:"url,attr,omitempty"`
+}
+
+type WebhookDestination struct {
+	HTTP *WebhookDestinationHTTP `json:"webhook-destination-http,omitempty" xml:"webhook-destination-http,omitempty"`
+}
+
+type Webhook struct {
+	ID                 string              `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name               string              `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Event              string              `json:"event,omitempty" xml:"event,attr,omitempty"`
+	IsEnabled          bool                `json:"isEnabled,omitempty" xml:"isEnabled,attr,omitempty"`
+	StatusChangeReason string              `json:"statusChangeReason,omitempty" xml:"statusChangeReason,attr,omitempty"`
+	CreatedAt          *time.Time          `json:"createdAt,omitempty" xml:"createdAt,attr,omitempty"`
+	UpdatedAt          *time.Time          `json:"updatedAt,omitempty" xml:"updatedAt,attr,omitempty"`
+	Owner              *User               `json:"owner,omitempty" xml:"owner,omitempty"`
+	Destination        *WebhookDestination `json:"webhook-destination,omitempty" xml:"webhook-destination,omitempty"`
+}
+
+func NewWebhook(name string, event string, destinationUrl string) Webhook {
+	return Webhook{
+		Name:        name,
+		Event:       event,
+		Destination: &WebhookDestination{HTTP: &WebhookDestinationHTTP{Method: POST, Url: destinationUrl}},
+	}
+}
+
+type Webhooks struct {
+	Webhooks []Webhook `json:"webhook,omitempty" xml:"webhook,omitempty"`
+}
+
+type CreateWebhookRequest struct {
+	Request Webhook `json:"webhook,omitempty" xml:"webhook,omitempty"`
+}
+
+func (req CreateWebhookRequest) XML() ([]byte, error) {
+	tmp := struct {
+		CreateWebhookRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{CreateWebhookRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
+type WebhookResponse struct {
+	Webhook Webhook `json:"webhook,omitempty" xml:"webhook,omitempty"`
+}
+
+type QueryWebhooksResponse struct {
+	Webhooks Webhooks `json:"webhooks,omitempty" xml:"webhooks,omitempty"`
+}
+
+type WebhookTestResult struct {
+	ID     string `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Status int    `json:"status,string,omitempty" xml:"status,attr,omitempty"`
+	Body   string `json:"body,omitempty" xml:"body,omitempty"`
+}
+
+type TestWebhookResponse struct {
+	WebhookTestResult WebhookTestResult `json:"webhookTestResult,omitempty" xml:"webhookTestResult,omitempty"`
+}
+
 type SigninRequest struct {
 	Request Credentials `json:"credentials,omitempty" xml:"credentials,omitempty"`
 }
@@ -123,6 +614,18 @@ func (req SigninRequest) XML() ([]byte, error) {
 	return xml.MarshalIndent(tmp, "", "   ")
 }
 
+type SwitchSiteRequest struct {
+	Request Site `json:"site,omitempty" xml:"site,omitempty"`
+}
+
+func (req SwitchSiteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		SwitchSiteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{SwitchSiteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
+}
+
 type AuthResponse struct {
 	Credentials *Credentials `json:"credentials,omitempty" xml:"credentials,omitempty"`
 }
@@ -168,7 +671,7 @@ func (req QuerySitesResponse) XML() ([]byte, error) {
 }
 
 type Sites struct {
-	Sites []Site `json:"sites" xml:"sites,attr"`
+	Sites []Site `json:"site,omitempty" xml:"site,omitempty"`
 }
 
 type QuerySiteResponse struct {
@@ -195,21 +698,53 @@ func (req QueryUserOnSiteResponse) XML() ([]byte, error) {
 	return xml.MarshalIndent(tmp, "", "   ")
 }
 
+const ADMIN_MODE_CONTENT_AND_USERS = "ContentAndUsers"
+const ADMIN_MODE_CONTENT_ONLY = "ContentOnly"
+
+// Site settings that are on/off are *bool so an update can turn them off; nil
+// leaves the server value alone. Use Bool to fill them in.
 type Site struct {
-	ID           string     `json:"id,omitempty" xml:"id,attr,omitempty"`
-	Name         string     `json:"name,omitempty" xml:"name,attr,omitempty"`
-	ContentUrl   string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
-	AdminMode    string     `json:"adminMode,omitempty" xml:"adminMode,attr,omitempty"`
-	UserQuota    string     `json:"userQuota,omitempty" xml:"userQuota,attr,omitempty"`
-	StorageQuota int        `json:"storageQuota,omitempty" xml:"storageQuota,attr,omitempty"`
-	State        string     `json:"state,omitempty" xml:"state,attr,omitempty"`
-	StatusReason string     `json:"statusReason,omitempty" xml:"statusReason,attr,omitempty"`
-	Usage        *SiteUsage `json:"usage,omitempty" xml:"usage,omitempty"`
+	ID                     string     `json:"id,omitempty" xml:"id,attr,omitempty"`
+	Name                   string     `json:"name,omitempty" xml:"name,attr,omitempty"`
+	ContentUrl             string     `json:"contentUrl,omitempty" xml:"contentUrl,attr,omitempty"`
+	AdminMode              string     `json:"adminMode,omitempty" xml:"adminMode,attr,omitempty"`
+	UserQuota              string     `json:"userQuota,omitempty" xml:"userQuota,attr,omitempty"`
+	StorageQuota           int        `json:"storageQuota,string,omitempty" xml:"storageQuota,attr,omitempty"`
+	State                  string     `json:"state,omitempty" xml:"state,attr,omitempty"`
+	StatusReason           string     `json:"statusReason,omitempty" xml:"statusReason,attr,omitempty"`
+	DisableSubscriptions   *bool      `json:"disableSubscriptions,omitempty" xml:"disableSubscriptions,attr,omitempty"`
+	SubscribeOthersEnabled *bool      `json:"subscribeOthersEnabled,omitempty" xml:"subscribeOthersEnabled,attr,omitempty"`
+	RevisionHistoryEnabled *bool      `json:"revisionHistoryEnabled,omitempty" xml:"revisionHistoryEnabled,attr,omitempty"`
+	RevisionLimit          int        `json:"revisionLimit,string,omitempty" xml:"revisionLimit,attr,omitempty"`
+	GuestAccessEnabled     *bool      `json:"guestAccessEnabled,omitempty" xml:"guestAccessEnabled,attr,omitempty"`
+	FlowsEnabled           *bool      `json:"flowsEnabled,omitempty" xml:"flowsEnabled,attr,omitempty"`
+	CacheWarmupEnabled     *bool      `json:"cacheWarmupEnabled,omitempty" xml:"cacheWarmupEnabled,attr,omitempty"`
+	CommentingEnabled      *bool      `json:"commentingEnabled,omitempty" xml:"commentingEnabled,attr,omitempty"`
+	DataAlertsEnabled      *bool      `json:"dataAlertsEnabled,omitempty" xml:"dataAlertsEnabled,attr,omitempty"`
+	ExtractEncryptionMode  string     `json:"extractEncryptionMode,omitempty" xml:"extractEncryptionMode,attr,omitempty"`
+	TimeZone               string     `json:"timeZone,omitempty" xml:"timeZone,attr,omitempty"`
+	Usage                  *SiteUsage `json:"usage,omitempty" xml:"usage,omitempty"`
+}
+
+func Bool(b bool) *bool {
+	return &b
+}
+
+type SiteRequest struct {
+	Request Site `json:"site,omitempty" xml:"site,omitempty"`
+}
+
+func (req SiteRequest) XML() ([]byte, error) {
+	tmp := struct {
+		SiteRequest
+		XMLName struct{} `xml:"tsRequest"`
+	}{SiteRequest: req}
+	return xml.MarshalIndent(tmp, "", "   ")
 }
 
 type SiteUsage struct {
-	NumberOfUsers int `json:"number-of-users" xml:"number-of-users,attr"`
-	Storage       int `json:"storage" xml:"storage,attr"`
+	NumberOfUsers int `json:"number-of-users,string" xml:"number-of-users,attr"`
+	Storage       int `json:"storage,string" xml:"storage,attr"`
 }
 
 type ConnectionCredentials struct {
@@ -0,0 +1,102 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CallInfo describes a call as it starts. Operation is the API method that
+// made it, e.g. QueryProjects.
+type CallInfo struct {
+	Operation string
+	Method    string
+	URL       string
+	SiteID    string
+}
+
+// CallResult describes a finished call, retries included. ErrorCode is the
+// Tableau error code (e.g. 409009) when the server sent one.
+type CallResult struct {
+	StatusCode int
+	ErrorCode  string
+	Err        error
+	Duration   time.Duration
+}
+
+// Observer is told when each call starts and gets back a func to call when it
+// ends, which is what tracing and metrics need. The context it returns is the
+// one the call's requests are made with, so a span started in StartCall is the
+// parent of anything the transport does.
+type Observer interface {
+	StartCall(ctx context.Context, call CallInfo) (context.Context, func(CallResult))
+}
+
+var siteIdInUrl = regexp.MustCompile(`/sites/([^/?]+)`)
+
+// startCall hands the call to api.Observer and returns the context to make it
+// with, the returned func is never nil.
+func (api *API) startCall(method string, requestUrl string) (context.Context, func(statusCode int, err error)) {
+	if api.Observer == nil {
+		return api.context(), func(int, error) {}
+	}
+	siteId := api.SiteID()
+	if match := siteIdInUrl.FindStringSubmatch(requestUrl); match != nil && !strings.Contains(requestUrl, "key=") {
+		siteId = match[1]
+	}
+	start := time.Now()
+	ctx, end := api.Observer.StartCall(api.context(), CallInfo{Operation: operationName(), Method: method, URL: requestUrl, SiteID: siteId})
+	return ctx, func(statusCode int, err error) {
+		result := CallResult{StatusCode: statusCode, Err: err, Duration: time.Since(start)}
+		apiErr := &APIError{}
+		if errors.As(err, &apiErr) {
+			result.ErrorCode = apiErr.Code
+			result.StatusCode = apiErr.StatusCode
+		}
+		end(result)
+	}
+}
+
+// operationName walks up the stack to the nearest exported *API method, so
+// GetProjectByName shows up as the QueryProjects it calls.
+func operationName() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	prefix := apiMethodPrefix()
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, prefix) {
+			name := frame.Function[len(prefix):]
+			if len(name) > 0 && unicode.IsUpper(rune(name[0])) && !strings.Contains(name, ".") {
+				return name
+			}
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// apiMethodPrefix is this package's "<import path>.(*API)." so forks and
+// vendored copies still match.
+func apiMethodPrefix() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	return name[:strings.LastIndex(name, ".apiMethodPrefix")] + ".(*API)."
+}
@@ -0,0 +1,104 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattbaird/tableau4go"
+	"github.com/mattbaird/tableau4go/tableautest"
+)
+
+type ctxKey struct{}
+
+// recordingObserver tags the context it hands back, so the transport can tell
+// it is the one the request was made with.
+type recordingObserver struct {
+	calls   []tableau4go.CallInfo
+	results []tableau4go.CallResult
+}
+
+func (o *recordingObserver) StartCall(ctx context.Context, call tableau4go.CallInfo) (context.Context, func(tableau4go.CallResult)) {
+	o.calls = append(o.calls, call)
+	return context.WithValue(ctx, ctxKey{}, call.Operation), func(result tableau4go.CallResult) {
+		o.results = append(o.results, result)
+	}
+}
+
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestObserverContextReachesTransport(t *testing.T) {
+	server := tableautest.NewServer()
+	defer server.Close()
+	observer := &recordingObserver{}
+	var seen []interface{}
+	api := server.API()
+	api.Observer = observer
+	api.HTTPClient = &http.Client{Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+		seen = append(seen, req.Context().Value(ctxKey{}))
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	if _, err := api.ServerInfo(); err != nil {
+		t.Fatal(err)
+	}
+	if len(observer.calls) != 1 || observer.calls[0].Operation != "ServerInfo" {
+		t.Fatalf("calls = %+v", observer.calls)
+	}
+	if len(seen) != 1 || seen[0] != "ServerInfo" {
+		t.Errorf("request context carried %v, want the observer's", seen)
+	}
+	if len(observer.results) != 1 || observer.results[0].StatusCode != http.StatusOK {
+		t.Errorf("results = %+v", observer.results)
+	}
+}
+
+func TestCancelStopsRetryWait(t *testing.T) {
+	server := tableautest.NewServer()
+	defer server.Close()
+	server.FailNext(http.StatusServiceUnavailable, "503000", "Service Unavailable", "down")
+	api := server.API()
+	api.RetryPolicy = &tableau4go.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := api.WithContext(ctx).ServerInfo()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("returned after %v, the backoff was not cut short", elapsed)
+	}
+}
+
+func TestCancelStopsLimiterWait(t *testing.T) {
+	server := tableautest.NewServer()
+	defer server.Close()
+	api := server.API()
+	// one request a minute, the first takes the only token
+	api.Limiter = tableau4go.NewLimiter(1.0/60, 1, 0)
+	if _, err := api.ServerInfo(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := api.WithContext(ctx).ServerInfo(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
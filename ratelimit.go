@@ -0,0 +1,175 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Limiter keeps an API under the server's rate limits: a token bucket of
+// RequestsPerSecond refilling up to Burst, and at most MaxInFlight requests
+// open at once. Every HTTP attempt, retries included, goes through it. It is
+// held by pointer so copies of an API share one budget.
+type Limiter struct {
+	requestsPerSecond float64
+	burst             int
+	maxInFlight       int
+	slots             chan struct{}
+
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	requests     int64
+	throttled    int64
+	throttleWait time.Duration
+	inFlight     int
+	peakInFlight int
+}
+
+// LimiterMetrics is a snapshot of a Limiter's settings and counters.
+type LimiterMetrics struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxInFlight       int
+	// Requests is every request let through so far
+	Requests int64
+	// Throttled is how many of them waited on the token bucket, for ThrottleWait in total
+	Throttled    int64
+	ThrottleWait time.Duration
+	InFlight     int
+	PeakInFlight int
+}
+
+// NewLimiter takes 0 for requestsPerSecond or maxInFlight to leave that side
+// unlimited. burst below 1 is treated as 1.
+func NewLimiter(requestsPerSecond float64, burst int, maxInFlight int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &Limiter{requestsPerSecond: requestsPerSecond, burst: burst, maxInFlight: maxInFlight, tokens: float64(burst), last: time.Now()}
+	if maxInFlight > 0 {
+		l.slots = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// acquire blocks until a request may go out or ctx is done. The returned func
+// gives back its in-flight slot and must be called exactly once.
+func (l *Limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	wait := l.reserve()
+	if err := sleep(ctx, wait); err != nil {
+		l.unreserve(wait)
+		return nil, err
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.unreserve(wait)
+			return nil, ctx.Err()
+		}
+	}
+	l.mu.Lock()
+	l.requests++
+	l.inFlight++
+	if l.inFlight > l.peakInFlight {
+		l.peakInFlight = l.inFlight
+	}
+	l.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.inFlight--
+			l.mu.Unlock()
+			if l.slots != nil {
+				<-l.slots
+			}
+		})
+	}, nil
+}
+
+// reserve takes a token, letting the bucket go negative, and returns how long
+// the caller has to wait for that token to have existed.
+func (l *Limiter) reserve() time.Duration {
+	if l.requestsPerSecond <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.requestsPerSecond
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	wait := time.Duration(-l.tokens / l.requestsPerSecond * float64(time.Second))
+	l.throttled++
+	l.throttleWait += wait
+	return wait
+}
+
+// unreserve gives back the token of a request that was cancelled before it went
+// out, so that cancellations don't drain the bucket, and takes its wait back off
+// the throttle counts.
+func (l *Limiter) unreserve(wait time.Duration) {
+	if l.requestsPerSecond <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if wait > 0 {
+		l.throttled--
+		l.throttleWait -= wait
+	}
+}
+
+func (l *Limiter) Metrics() LimiterMetrics {
+	if l == nil {
+		return LimiterMetrics{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LimiterMetrics{
+		RequestsPerSecond: l.requestsPerSecond,
+		Burst:             l.burst,
+		MaxInFlight:       l.maxInFlight,
+		Requests:          l.requests,
+		Throttled:         l.throttled,
+		ThrottleWait:      l.throttleWait,
+		InFlight:          l.inFlight,
+		PeakInFlight:      l.peakInFlight,
+	}
+}
+
+// releaseOnClose holds the in-flight slot until the caller is done with the body.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
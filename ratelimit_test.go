@@ -0,0 +1,188 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func acquireOrFail(t *testing.T, l *Limiter) func() {
+	t.Helper()
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return release
+}
+
+func TestLimiterRate(t *testing.T) {
+	l := NewLimiter(50, 1, 0)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		acquireOrFail(t, l)()
+	}
+	// the first goes at once, the other five a fiftieth of a second apart
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond || elapsed > time.Second {
+		t.Errorf("6 requests at 50/s took %v, want about 100ms", elapsed)
+	}
+	if m := l.Metrics(); m.Requests != 6 || m.Throttled != 5 || m.ThrottleWait <= 0 {
+		t.Errorf("Metrics() = %+v", m)
+	}
+}
+
+func TestLimiterBurst(t *testing.T) {
+	l := NewLimiter(1, 5, 0)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		acquireOrFail(t, l)()
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("a burst of 5 took %v", elapsed)
+	}
+	if m := l.Metrics(); m.Throttled != 0 || m.Burst != 5 || m.RequestsPerSecond != 1 {
+		t.Errorf("Metrics() = %+v", m)
+	}
+	// the sixth waits for the bucket to refill a token at 1/s
+	if wait := l.reserve(); wait < 900*time.Millisecond || wait > time.Second {
+		t.Errorf("sixth request waits %v, want about 1s", wait)
+	}
+}
+
+func TestLimiterMaxInFlight(t *testing.T) {
+	l := NewLimiter(0, 0, 2)
+	var current, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.acquire(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&current, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+			release()
+			release() // a second release must not free another slot
+		}()
+	}
+	wg.Wait()
+	if peak > 2 {
+		t.Errorf("%d requests in flight at once, MaxInFlight is 2", peak)
+	}
+	if m := l.Metrics(); m.Requests != 10 || m.InFlight != 0 || m.PeakInFlight != 2 || m.MaxInFlight != 2 {
+		t.Errorf("Metrics() = %+v", m)
+	}
+}
+
+func TestLimiterRefundsCancelledRequests(t *testing.T) {
+	// one request a minute: without the refund each cancellation would push
+	// the next request another minute out
+	l := NewLimiter(1.0/60, 1, 0)
+	acquireOrFail(t, l)()
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+		}
+		cancel()
+	}
+	if wait := l.reserve(); wait > time.Minute {
+		t.Errorf("after 20 cancellations the next request waits %v", wait)
+	}
+	if m := l.Metrics(); m.Requests != 1 || m.Throttled != 1 {
+		t.Errorf("Metrics() = %+v, want the cancelled requests left out", m)
+	}
+
+	// a request cancelled while waiting for a slot gives its token back too
+	l = NewLimiter(1.0/60, 2, 1)
+	release := acquireOrFail(t, l)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	release()
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < 1 {
+		t.Errorf("%v tokens left, want the cancelled request's back", tokens)
+	}
+}
+
+func TestLimiterHoldsSlotUntilBodyClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+	api := &API{Limiter: NewLimiter(0, 0, 1)}
+	resp, err := api.send(context.Background(), server.URL, GET, nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := api.Limiter.Metrics(); m.InFlight != 1 {
+		t.Errorf("InFlight = %d with the body open", m.InFlight)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := api.send(ctx, server.URL, GET, nil, nil, false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("second request with the only slot held: err = %v", err)
+	}
+	resp.Body.Close()
+	if m := api.Limiter.Metrics(); m.InFlight != 0 {
+		t.Errorf("InFlight = %d after Close", m.InFlight)
+	}
+
+	// a download keeps its slot while it streams into the writer
+	var streamedInFlight int
+	w := writerFunc(func(p []byte) (int, error) {
+		streamedInFlight = api.Limiter.Metrics().InFlight
+		return len(p), nil
+	})
+	if err := api.download(server.URL, w, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m := api.Limiter.Metrics(); streamedInFlight != 1 || m.InFlight != 0 || m.Requests != 2 {
+		t.Errorf("InFlight = %d while streaming, metrics after = %+v", streamedInFlight, m)
+	}
+}
+
+func TestNilLimiter(t *testing.T) {
+	var l *Limiter
+	release := acquireOrFail(t, l)
+	release()
+	if m := l.Metrics(); m != (LimiterMetrics{}) {
+		t.Errorf("Metrics() = %+v", m)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
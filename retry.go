@@ -0,0 +1,200 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries transport errors and transient statuses with exponential
+// backoff. Only GET, HEAD, DELETE and calls known to be safe (sign in, Metadata
+// API and VizQL Data Service reads) are retried unless RetryUnsafe is set,
+// since a POST or PUT that reached the server may already have taken effect.
+// A Retry-After is waited out in full, one longer than MaxBackoff ends the
+// retries instead.
+type RetryPolicy struct {
+	// MaxAttempts counts the first try, 1 or less disables retrying
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each wait by up to this fraction either way, 0 to 1
+	Jitter float64
+	// RetryableStatuses defaults to 429, 502, 503 and 504 when empty
+	RetryableStatuses []int
+	RetryUnsafe       bool
+}
+
+var default_retryable_statuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+func (p *RetryPolicy) attempts(method string, safe bool) int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	if !safe && !isIdempotent(method) && !p.RetryUnsafe {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	statuses := p.RetryableStatuses
+	if len(statuses) == 0 {
+		statuses = default_retryable_statuses
+	}
+	for _, status := range statuses {
+		if status == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff is the wait before attempt+1, attempt counting from 1. It is worked
+// out and capped as a float, a high attempt overflows a Duration.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	if p.InitialBackoff <= 0 {
+		return 0
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && wait > float64(p.MaxBackoff) {
+		wait = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if wait >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return p.capped(time.Duration(wait))
+}
+
+func (p *RetryPolicy) capped(wait time.Duration) time.Duration {
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+func isIdempotent(method string) bool {
+	return method == GET || method == DELETE || method == http.MethodHead
+}
+
+// retryAfter reads a Retry-After header given either as seconds or as an http date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if len(value) == 0 {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+	return 0, false
+}
+
+// send issues the request, retrying as api.RetryPolicy allows, and returns the
+// last response for the caller to close. safe marks a non idempotent call that
+// can be repeated without side effects. Cancelling ctx ends the request and any
+// wait for a retry or the Limiter.
+func (api *API) send(ctx context.Context, requestUrl string, method string, payload []byte, headers map[string]string, safe bool) (*http.Response, error) {
+	client := api.HTTPClient
+	if client == nil {
+		client = DefaultTimeoutClient()
+	}
+	policy := api.RetryPolicy
+	attempts := policy.attempts(method, safe)
+	for attempt := 1; ; attempt++ {
+		req, err := api.newRequest(ctx, requestUrl, method, payload, headers)
+		if err != nil {
+			return nil, err
+		}
+		api.logBody(ctx, "tableau request body", method, requestUrl, req.Header, payload)
+		release, err := api.Limiter.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		api.logRequest(req, resp, err, attempt, time.Since(start))
+		if err != nil {
+			release()
+		} else {
+			resp.Body = releaseOnClose{ReadCloser: resp.Body, release: release}
+		}
+		if attempt >= attempts {
+			return resp, err
+		}
+		var wait time.Duration
+		if err != nil {
+			wait = policy.backoff(attempt)
+		} else if policy.retryableStatus(resp.StatusCode) {
+			wait = policy.backoff(attempt)
+			if after, ok := retryAfter(resp); ok {
+				// coming back early only prolongs a lockout, so a server that
+				// asks for longer than MaxBackoff gets its answer handed back
+				if policy.MaxBackoff > 0 && after > policy.MaxBackoff {
+					return resp, nil
+				}
+				wait = policy.capped(after)
+			}
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			return resp, nil
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleep waits for d unless ctx is done first, returning ctx's error then.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	p := DefaultRetryPolicy()
+	if p.MaxAttempts != 4 || p.InitialBackoff != 500*time.Millisecond || p.MaxBackoff != 30*time.Second || p.Multiplier != 2 || p.Jitter != 0.2 {
+		t.Errorf("DefaultRetryPolicy() = %+v", p)
+	}
+	for _, status := range []int{429, 502, 503, 504} {
+		if !p.retryableStatus(status) {
+			t.Errorf("%d is not retryable by default", status)
+		}
+	}
+	for _, status := range []int{400, 401, 404, 409, 500} {
+		if p.retryableStatus(status) {
+			t.Errorf("%d is retryable by default", status)
+		}
+	}
+}
+
+func TestBackoffJitterBounds(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2, Jitter: 0.25}
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		low, high := base*3/4, base*5/4
+		for i := 0; i < 200; i++ {
+			if wait := p.backoff(attempt); wait < low || wait > high {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, wait, low, high)
+			}
+		}
+	}
+	for i := 0; i < 200; i++ {
+		if wait := p.backoff(10); wait > p.MaxBackoff {
+			t.Fatalf("backoff(10) = %v, over MaxBackoff %v", wait, p.MaxBackoff)
+		}
+	}
+	p.Jitter = 0
+	if wait := p.backoff(3); wait != 400*time.Millisecond {
+		t.Errorf("backoff(3) without jitter = %v", wait)
+	}
+}
+
+func TestBackoffHighAttempts(t *testing.T) {
+	p := DefaultRetryPolicy()
+	p.Jitter = 0
+	// 500ms doubling passes MaxInt64 nanoseconds at attempt 36
+	for _, attempt := range []int{10, 35, 36, 40, 60, 1000, 100000} {
+		if wait := p.backoff(attempt); wait != p.MaxBackoff {
+			t.Errorf("backoff(%d) = %v, want MaxBackoff %v", attempt, wait, p.MaxBackoff)
+		}
+	}
+	p.Jitter = 0.2
+	for _, attempt := range []int{36, 60, 1000} {
+		if wait := p.backoff(attempt); wait < p.MaxBackoff*4/5 || wait > p.MaxBackoff {
+			t.Errorf("backoff(%d) with jitter = %v", attempt, wait)
+		}
+	}
+	uncapped := &RetryPolicy{InitialBackoff: time.Second, Multiplier: 2}
+	if wait := uncapped.backoff(100); wait != time.Duration(math.MaxInt64) {
+		t.Errorf("backoff(100) without MaxBackoff = %v", wait)
+	}
+	if wait := (&RetryPolicy{Multiplier: 2}).backoff(2000); wait != 0 {
+		t.Errorf("backoff(2000) without InitialBackoff = %v", wait)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	if _, ok := retryAfter(resp); ok {
+		t.Error("retryAfter without the header")
+	}
+	resp.Header.Set("Retry-After", "7")
+	if wait, ok := retryAfter(resp); !ok || wait != 7*time.Second {
+		t.Errorf("retryAfter(7) = %v, %v", wait, ok)
+	}
+	resp.Header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	if wait, ok := retryAfter(resp); !ok || wait < 55*time.Second || wait > time.Minute {
+		t.Errorf("retryAfter(date a minute out) = %v, %v", wait, ok)
+	}
+	resp.Header.Set("Retry-After", "soon")
+	if _, ok := retryAfter(resp); ok {
+		t.Error("retryAfter(soon) parsed")
+	}
+}
+
+func TestAttemptsIdempotencyGate(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 3}
+	cases := []struct {
+		method string
+		safe   bool
+		unsafe bool
+		want   int
+	}{
+		{GET, false, false, 3},
+		{DELETE, false, false, 3},
+		{http.MethodHead, false, false, 3},
+		{POST, false, false, 1},
+		{PUT, false, false, 1},
+		{POST, true, false, 3},
+		{POST, false, true, 3},
+		{PUT, false, true, 3},
+	}
+	for _, c := range cases {
+		p.RetryUnsafe = c.unsafe
+		if got := p.attempts(c.method, c.safe); got != c.want {
+			t.Errorf("attempts(%s, safe=%v) with RetryUnsafe=%v = %d, want %d", c.method, c.safe, c.unsafe, got, c.want)
+		}
+	}
+	var none *RetryPolicy
+	if got := none.attempts(GET, false); got != 1 {
+		t.Errorf("nil policy attempts = %d", got)
+	}
+}
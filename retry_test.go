@@ -0,0 +1,178 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+// countRequests counts what api sends from here on.
+func countRequests(api *tableau4go.API) *int32 {
+	var count int32
+	api.HTTPClient = &http.Client{Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&count, 1)
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	return &count
+}
+
+func fastRetries() *tableau4go.RetryPolicy {
+	return &tableau4go.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+}
+
+func TestRetryTransientStatuses(t *testing.T) {
+	for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+		server, api := signedIn(t)
+		api.RetryPolicy = fastRetries()
+		count := countRequests(api)
+		server.FailNext(status, "", http.StatusText(status), "try again")
+		server.FailNext(status, "", http.StatusText(status), "try again")
+		if _, err := api.QueryProjects(api.SiteID()); err != nil {
+			t.Fatalf("%d twice: %v", status, err)
+		}
+		if *count != 3 {
+			t.Errorf("%d twice took %d attempts, want 3", status, *count)
+		}
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	server, api := signedIn(t)
+	api.RetryPolicy = fastRetries()
+	count := countRequests(api)
+	for i := 0; i < 3; i++ {
+		server.FailNext(http.StatusServiceUnavailable, "", "Service Unavailable", "down")
+	}
+	_, err := api.QueryProjects(api.SiteID())
+	var apiErr *tableau4go.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("err = %v, want the last 503", err)
+	}
+	if *count != 3 {
+		t.Errorf("%d attempts, want 3", *count)
+	}
+}
+
+func TestRetrySkipsNonRetryableStatus(t *testing.T) {
+	server, api := signedIn(t)
+	api.RetryPolicy = fastRetries()
+	count := countRequests(api)
+	server.FailNext(http.StatusInternalServerError, "500000", "Internal Server Error", "boom")
+	if _, err := api.QueryProjects(api.SiteID()); err == nil {
+		t.Fatal("500 succeeded")
+	}
+	if *count != 1 {
+		t.Errorf("%d attempts at a 500, want 1", *count)
+	}
+}
+
+func TestRetryPostOnlyWhenSafe(t *testing.T) {
+	server, api := signedIn(t)
+	api.RetryPolicy = fastRetries()
+	count := countRequests(api)
+	server.FailNext(http.StatusServiceUnavailable, "", "Service Unavailable", "down")
+	if _, err := api.CreateProject(api.SiteID(), tableau4go.Project{Name: "once"}); err == nil {
+		t.Fatal("POST was retried past a 503")
+	}
+	if *count != 1 {
+		t.Errorf("POST took %d attempts, want 1", *count)
+	}
+
+	// sign in is marked safe
+	*count = 0
+	server.FailNext(http.StatusServiceUnavailable, "", "Service Unavailable", "down")
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if *count != 2 {
+		t.Errorf("sign in took %d attempts, want 2", *count)
+	}
+
+	*count = 0
+	api.RetryPolicy.RetryUnsafe = true
+	server.FailNext(http.StatusServiceUnavailable, "", "Service Unavailable", "down")
+	if _, err := api.CreateProject(api.SiteID(), tableau4go.Project{Name: "twice"}); err != nil {
+		t.Fatal(err)
+	}
+	if *count != 2 {
+		t.Errorf("POST with RetryUnsafe took %d attempts, want 2", *count)
+	}
+}
+
+func TestNoRetryPolicyMeansOneAttempt(t *testing.T) {
+	server, api := signedIn(t)
+	count := countRequests(api)
+	server.FailNext(http.StatusServiceUnavailable, "", "Service Unavailable", "down")
+	if _, err := api.QueryProjects(api.SiteID()); err == nil {
+		t.Fatal("503 succeeded without a RetryPolicy")
+	}
+	if *count != 1 {
+		t.Errorf("%d attempts, want 1", *count)
+	}
+}
+
+// throttleOnce answers api's next request with a 429 asking for retryAfter,
+// and passes the rest through.
+func throttleOnce(api *tableau4go.API, retryAfter string) *int32 {
+	var count int32
+	api.HTTPClient = &http.Client{Transport: transportFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": {retryAfter}},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				Request:    req,
+			}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	return &count
+}
+
+func TestRetryAfterIsWaitedOut(t *testing.T) {
+	_, api := signedIn(t)
+	api.RetryPolicy = &tableau4go.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	count := throttleOnce(api, "1")
+	start := time.Now()
+	if _, err := api.QueryProjects(api.SiteID()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, the server asked for 1s", elapsed)
+	}
+	if *count != 2 {
+		t.Errorf("%d attempts, want 2", *count)
+	}
+}
+
+func TestRetryAfterPastMaxBackoffGivesUp(t *testing.T) {
+	_, api := signedIn(t)
+	api.RetryPolicy = tableau4go.DefaultRetryPolicy()
+	count := throttleOnce(api, "120")
+	start := time.Now()
+	_, err := api.QueryProjects(api.SiteID())
+	var apiErr *tableau4go.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want the 429", err)
+	}
+	if *count != 1 || time.Since(start) > 5*time.Second {
+		t.Errorf("%d attempts in %v, want the 429 back at once", *count, time.Since(start))
+	}
+}
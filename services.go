@@ -0,0 +1,190 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// The calls of API grouped by resource, so code can depend on just the part it
+// uses and tests can hand it a tableaumock in place of a server. Client is all
+// of them together. Clone, WithContext and ForEachSite hand out *API and stay on
+// the concrete type.
+//
+// Run go generate in tableaumock after changing these.
+
+type AuthService interface {
+	Signin(username, password string, contentUrl string, userIdToImpersonate string) error
+	SwitchSite(contentUrl string) error
+	Signout() error
+	AuthToken() string
+	SiteID() string
+	UserID() string
+	SetAuthToken(token string, siteId string)
+}
+
+type ServerService interface {
+	ServerInfo() (ServerInfo, error)
+}
+
+type SiteService interface {
+	QuerySites() ([]Site, error)
+	QuerySite(siteID string, includeStorage bool) (Site, error)
+	QuerySiteByName(name string, includeStorage bool) (Site, error)
+	QuerySiteByContentUrl(contentUrl string, includeStorage bool) (Site, error)
+	GetSiteID(siteName string) (string, error)
+	CreateSite(site Site) (*Site, error)
+	UpdateSite(siteId string, site Site) (*Site, error)
+	DeleteSite(siteId string) error
+	DeleteSiteByName(name string) error
+	DeleteSiteByContentUrl(contentUrl string) error
+}
+
+type UserService interface {
+	QueryUserOnSite(siteId, userId string) (User, error)
+}
+
+type ProjectService interface {
+	QueryProjects(siteId string) ([]Project, error)
+	GetProjectByName(siteId, name string) (Project, error)
+	GetProjectByID(siteId, ID string) (Project, error)
+	CreateProject(siteId string, project Project) (*Project, error)
+	DeleteProject(siteId string, projectId string) error
+}
+
+type DatasourceService interface {
+	QueryDatasources(siteId string) ([]Datasource, error)
+	PublishTDS(siteId string, tdsMetadata Datasource, fullTds string, overwrite bool) (*Datasource, error)
+	CertifyDatasource(siteId string, datasourceId string, certificationNote string) (*Datasource, error)
+	UncertifyDatasource(siteId string, datasourceId string) (*Datasource, error)
+	DeleteDatasource(siteId string, datasourceId string) error
+}
+
+type WorkbookService interface {
+	DownloadWorkbookPDF(siteId, workbookId string, options PDFOptions, w io.Writer) error
+	DownloadWorkbookPowerPoint(siteId, workbookId string, options PowerPointOptions, w io.Writer) error
+}
+
+type ViewService interface {
+	QueryViewsForSite(siteId string, includeUsageStatistics bool) ([]View, error)
+	QueryViewsForWorkbook(siteId, workbookId string, includeUsageStatistics bool) ([]View, error)
+	QueryView(siteId, viewId string) (View, error)
+	QueryViewImage(siteId, viewId string, options ImageOptions, w io.Writer) error
+	QueryViewPDF(siteId, viewId string, options PDFOptions, w io.Writer) error
+	QueryViewData(siteId, viewId string, options DataOptions, w io.Writer) error
+	DownloadViewCrosstabExcel(siteId, viewId string, options DataOptions, w io.Writer) error
+}
+
+type TagService interface {
+	AddTagsToWorkbook(siteId, workbookId string, labels ...string) ([]Tag, error)
+	DeleteTagFromWorkbook(siteId, workbookId, label string) error
+	AddTagsToDatasource(siteId, datasourceId string, labels ...string) ([]Tag, error)
+	DeleteTagFromDatasource(siteId, datasourceId, label string) error
+	AddTagsToView(siteId, viewId string, labels ...string) ([]Tag, error)
+	DeleteTagFromView(siteId, viewId, label string) error
+	AddTagsToFlow(siteId, flowId string, labels ...string) ([]Tag, error)
+	DeleteTagFromFlow(siteId, flowId, label string) error
+}
+
+type FavoriteService interface {
+	AddWorkbookToFavorites(siteId, userId, label, workbookId string) ([]Favorite, error)
+	AddViewToFavorites(siteId, userId, label, viewId string) ([]Favorite, error)
+	AddDatasourceToFavorites(siteId, userId, label, datasourceId string) ([]Favorite, error)
+	AddProjectToFavorites(siteId, userId, label, projectId string) ([]Favorite, error)
+	AddFlowToFavorites(siteId, userId, label, flowId string) ([]Favorite, error)
+	DeleteWorkbookFromFavorites(siteId, userId, workbookId string) error
+	DeleteViewFromFavorites(siteId, userId, viewId string) error
+	DeleteDatasourceFromFavorites(siteId, userId, datasourceId string) error
+	DeleteProjectFromFavorites(siteId, userId, projectId string) error
+	DeleteFlowFromFavorites(siteId, userId, flowId string) error
+	QueryFavoritesForUser(siteId, userId string) ([]Favorite, error)
+}
+
+type SubscriptionService interface {
+	CreateSubscription(siteId string, subscription Subscription) (*Subscription, error)
+	UpdateSubscription(siteId string, subscription Subscription) (*Subscription, error)
+	QuerySubscriptions(siteId string) ([]Subscription, error)
+	QuerySubscription(siteId string, subscriptionId string) (Subscription, error)
+	DeleteSubscription(siteId string, subscriptionId string) error
+}
+
+type DataAlertService interface {
+	QueryDataAlerts(siteId string) ([]DataAlert, error)
+	QueryDataAlert(siteId string, dataAlertId string) (DataAlert, error)
+	DeleteDataAlert(siteId string, dataAlertId string) error
+	AddUserToDataAlert(siteId string, dataAlertId string, userId string) (User, error)
+	DeleteUserFromDataAlert(siteId string, dataAlertId string, userId string) error
+	ChangeDataAlertOwner(siteId string, dataAlertId string, ownerId string) (*DataAlert, error)
+}
+
+type WebhookService interface {
+	CreateWebhook(siteId string, webhook Webhook) (*Webhook, error)
+	QueryWebhooks(siteId string) ([]Webhook, error)
+	QueryWebhook(siteId string, webhookId string) (Webhook, error)
+	TestWebhook(siteId string, webhookId string) (WebhookTestResult, error)
+	DeleteWebhook(siteId string, webhookId string) error
+}
+
+type FlowService interface {
+	QueryFlows(siteId string) ([]Flow, error)
+	QueryFlow(siteId string, flowId string) (Flow, []FlowOutputStep, error)
+	PublishFlow(siteId string, flowMetadata Flow, fileName string, flow []byte, overwrite bool) (*Flow, error)
+	DownloadFlow(siteId string, flowId string, w io.Writer) error
+	DeleteFlow(siteId string, flowId string) error
+	RunFlowNow(siteId string, flowId string) (*Job, error)
+	QueryFlowRuns(siteId string) ([]FlowRun, error)
+	QueryFlowRun(siteId string, flowRunId string) (FlowRun, error)
+	QueryFlowRunTasks(siteId string) ([]FlowRunTask, error)
+	QueryFlowRunTask(siteId string, taskId string) (FlowRunTask, error)
+}
+
+type JobService interface {
+	QueryJob(siteId string, jobId string) (Job, error)
+}
+
+type MetadataService interface {
+	MetadataQueryRaw(query string, variables map[string]interface{}) (json.RawMessage, error)
+	MetadataQuery(query string, variables map[string]interface{}, result interface{}) error
+	MetadataQueryAll(query string, variables map[string]interface{}, connection string, fn func(nodes json.RawMessage) error) error
+	QueryDatasourceLineage(datasourceId string) (MetadataDatasource, error)
+	QueryWorkbookLineage(workbookId string) (MetadataWorkbook, error)
+	QueryTableLineage(database, schema, table string) ([]MetadataTable, error)
+	TableImpact(database, schema, table string) (*LineageGraph, error)
+}
+
+type VizQLService interface {
+	VizQLReadMetadata(datasourceId string) ([]VizQLFieldMetadata, error)
+	VizQLQueryDatasource(datasourceId string, query *VizQLQuery) (*VizQLRows, error)
+}
+
+type Client interface {
+	AuthService
+	ServerService
+	SiteService
+	UserService
+	ProjectService
+	DatasourceService
+	WorkbookService
+	ViewService
+	TagService
+	FavoriteService
+	SubscriptionService
+	DataAlertService
+	WebhookService
+	FlowService
+	JobService
+	MetadataService
+	VizQLService
+}
+
+var _ Client = (*API)(nil)
@@ -0,0 +1,145 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"context"
+	"sync"
+)
+
+type session struct {
+	mu        sync.RWMutex
+	authToken string
+	siteID    string
+	userID    string
+}
+
+// state returns the session, creating it for an API built without NewAPI.
+func (api *API) state() *session {
+	api.sessionOnce.Do(func() {
+		if api.session == nil {
+			api.session = &session{}
+		}
+	})
+	return api.session
+}
+
+func (api *API) AuthToken() string {
+	s := api.state()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.authToken
+}
+
+// SiteID is the id of the site the session is signed in to.
+func (api *API) SiteID() string {
+	s := api.state()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.siteID
+}
+
+// UserID is the id of the signed in user, for calls such as QueryFavoritesForUser.
+func (api *API) UserID() string {
+	s := api.state()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.userID
+}
+
+// SetAuthToken uses a token obtained elsewhere, siteId may be blank if unknown.
+func (api *API) SetAuthToken(token string, siteId string) {
+	s := api.state()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authToken = token
+	s.siteID = siteId
+	s.userID = ""
+}
+
+// Clone returns a copy of api with the same settings and a session of its own,
+// starting from the current token. Signin or SwitchSite on the clone leave api
+// untouched, but until then both hold the same server side token, so a Signout on
+// either ends it for both, and so does a SwitchSite, which trades the old token
+// in. RetryPolicy and Limiter are shared, so clones still
+// count against one rate limit, and Logger and Observer are shared too.
+func (api *API) Clone() *API {
+	s := api.state()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	clone := api.settings()
+	clone.session = &session{authToken: s.authToken, siteID: s.siteID, userID: s.userID}
+	return clone
+}
+
+// WithContext returns a view of api that shares its session, so signing in or
+// out through either affects both, and makes its calls with ctx: cancelling it
+// stops the request along with any retry or Limiter wait, and the Observer
+// starts its spans under the caller's trace.
+func (api *API) WithContext(ctx context.Context) *API {
+	view := api.settings()
+	view.session = api.state()
+	view.ctx = ctx
+	return view
+}
+
+func (api *API) context() context.Context {
+	if api.ctx == nil {
+		return context.Background()
+	}
+	return api.ctx
+}
+
+func (api *API) settings() *API {
+	return &API{
+		Server:              api.Server,
+		Version:             api.Version,
+		Boundary:            api.Boundary,
+		OmitDefaultSiteName: api.OmitDefaultSiteName,
+		DefaultSiteName:     api.DefaultSiteName,
+		RetryPolicy:         api.RetryPolicy,
+		Limiter:             api.Limiter,
+		Logger:              api.Logger,
+		LogBodies:           api.LogBodies,
+		Observer:            api.Observer,
+		HTTPClient:          api.HTTPClient,
+		Format:              api.Format,
+		ctx:                 api.ctx,
+	}
+}
+
+func (api *API) setCredentials(credentials *Credentials) {
+	if credentials == nil {
+		return
+	}
+	s := api.state()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authToken = credentials.Token
+	s.siteID = ""
+	if credentials.Site != nil {
+		s.siteID = credentials.Site.ID
+	}
+	s.userID = ""
+	if credentials.Impersonate != nil {
+		s.userID = credentials.Impersonate.ID
+	}
+}
+
+func (api *API) clearSession() {
+	s := api.state()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authToken = ""
+	s.siteID = ""
+	s.userID = ""
+}
@@ -0,0 +1,126 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+	"github.com/mattbaird/tableau4go/tableautest"
+)
+
+// twoSites adds a second site, Other, with the same admin on it.
+func twoSites(t *testing.T) (*tableautest.Server, *tableau4go.API, tableau4go.Site) {
+	t.Helper()
+	server, api := signedIn(t)
+	other := server.AddSite(tableau4go.Site{Name: "Other", ContentUrl: "other"})
+	server.AddUser(other.ID, tableau4go.User{Name: "admin", SiteRole: "ServerAdministrator"}, "secret")
+	return server, api, other
+}
+
+// Run with -race: errors are expected, since goroutines sign each other out,
+// what matters is that the session is never read and written at once.
+func TestSessionConcurrentUse(t *testing.T) {
+	_, api, other := twoSites(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				switch (i + j) % 5 {
+				case 0:
+					api.Signin("admin", "secret", "", "")
+				case 1:
+					api.SwitchSite(other.ContentUrl)
+				case 2:
+					api.Signout()
+				case 3:
+					api.QueryProjects(api.SiteID())
+				case 4:
+					clone := api.Clone()
+					clone.Signin("admin", "secret", other.ContentUrl, "")
+					clone.QueryProjects(clone.SiteID())
+					clone.Signout()
+				}
+				_ = api.AuthToken()
+				_ = api.UserID()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCloneHasItsOwnSession(t *testing.T) {
+	_, api, other := twoSites(t)
+	token, siteID := api.AuthToken(), api.SiteID()
+	clone := api.Clone()
+	if clone.AuthToken() != token || clone.SiteID() != siteID || clone.UserID() != api.UserID() {
+		t.Fatalf("clone starts on %s/%s, want %s/%s", clone.AuthToken(), clone.SiteID(), token, siteID)
+	}
+	if err := clone.SwitchSite(other.ContentUrl); err != nil {
+		t.Fatal(err)
+	}
+	if clone.SiteID() != other.ID {
+		t.Errorf("clone SiteID = %s, want %s", clone.SiteID(), other.ID)
+	}
+	if api.AuthToken() != token || api.SiteID() != siteID {
+		t.Errorf("switching the clone moved api to %s/%s", api.AuthToken(), api.SiteID())
+	}
+	clone.SetAuthToken("elsewhere", "")
+	if api.AuthToken() != token {
+		t.Error("SetAuthToken on the clone changed api")
+	}
+	// the switch spent the token both started with, as Clone warns
+	if err := api.Signin("admin", "secret", other.ContentUrl, ""); err != nil {
+		t.Fatal(err)
+	}
+	if clone.AuthToken() != "elsewhere" {
+		t.Error("Signin on api changed the clone")
+	}
+}
+
+func TestWithContextSharesSession(t *testing.T) {
+	_, api, other := twoSites(t)
+	view := api.WithContext(context.Background())
+	if err := view.SwitchSite(other.ContentUrl); err != nil {
+		t.Fatal(err)
+	}
+	if api.SiteID() != other.ID || api.AuthToken() != view.AuthToken() {
+		t.Errorf("api is on %s after the view switched to %s", api.SiteID(), other.ID)
+	}
+	if _, err := api.QueryProjects(api.SiteID()); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.Signout(); err != nil {
+		t.Fatal(err)
+	}
+	if len(view.AuthToken()) > 0 || len(view.SiteID()) > 0 {
+		t.Errorf("view kept %s/%s after api signed out", view.AuthToken(), view.SiteID())
+	}
+}
+
+func TestZeroValueAPIHasASession(t *testing.T) {
+	server, _ := signedIn(t)
+	api := &tableau4go.API{Server: server.URL, Version: tableautest.REST_API_VERSION}
+	if len(api.AuthToken()) > 0 {
+		t.Fatal("fresh API has a token")
+	}
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.AuthToken()) == 0 || len(api.Clone().AuthToken()) == 0 {
+		t.Error("sign in on an API built without NewAPI kept no token")
+	}
+}
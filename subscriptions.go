@@ -0,0 +1,66 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#create_subscription
+func (api *API) CreateSubscription(siteId string, subscription Subscription) (*Subscription, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/subscriptions", api.Server, api.Version, siteId)
+	return api.sendSubscription(url, POST, subscription)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#update_subscription
+func (api *API) UpdateSubscription(siteId string, subscription Subscription) (*Subscription, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/subscriptions/%s", api.Server, api.Version, siteId, subscription.ID)
+	// the id is part of the url, the server rejects it in the body
+	subscription.ID = ""
+	return api.sendSubscription(url, PUT, subscription)
+}
+
+func (api *API) sendSubscription(url string, method string, subscription Subscription) (*Subscription, error) {
+	subscriptionRequest := SubscriptionRequest{Request: subscription}
+	headers := make(map[string]string)
+	payload, err := api.encode(subscriptionRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := SubscriptionResponse{}
+	err = api.makeRequest(url, method, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Subscription, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#query_subscriptions
+func (api *API) QuerySubscriptions(siteId string) ([]Subscription, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/subscriptions", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QuerySubscriptionsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Subscriptions.Subscriptions, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#query_subscription
+func (api *API) QuerySubscription(siteId string, subscriptionId string) (Subscription, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/subscriptions/%s", api.Server, api.Version, siteId, subscriptionId)
+	headers := make(map[string]string)
+	retval := SubscriptionResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Subscription, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_subscriptions.htm#delete_subscription
+func (api *API) DeleteSubscription(siteId string, subscriptionId string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/subscriptions/%s", api.Server, api.Version, siteId, subscriptionId)
+	return api.delete(url)
+}
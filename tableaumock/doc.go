@@ -0,0 +1,27 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tableaumock has mocks of the tableau4go service interfaces for testing
+// code without any HTTP. Set the Func field of each method the code under test
+// calls, an unset one panics so a call nobody expected shows up at once.
+//
+//	projects := &tableaumock.ProjectService{
+//		CreateProjectFunc: func(siteId string, project tableau4go.Project) (*tableau4go.Project, error) {
+//			project.ID = "p1"
+//			return &project, nil
+//		},
+//	}
+//	err := ensureProject(projects, siteId, "Sales")
+//
+// Client mocks the whole API, with the service mocks embedded.
+package tableaumock
+
+//go:generate go run gen.go
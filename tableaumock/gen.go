@@ -0,0 +1,173 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+// gen writes mocks.go from the interfaces in ../services.go: for every service
+// a struct with a Func field per method, and Client embedding them all.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+)
+
+const source = "../services.go"
+const target = "mocks.go"
+const library_path = "github.com/mattbaird/tableau4go"
+
+func main() {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, source, nil, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path := strings.Trim(spec.Path.Value, `"`)
+		imports[path[strings.LastIndex(path, "/")+1:]] = path
+	}
+	used := make(map[string]bool)
+	var body bytes.Buffer
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			iface, ok := typeSpec.Type.(*ast.InterfaceType)
+			if !ok {
+				continue
+			}
+			writeMock(&body, fset, typeSpec.Name.Name, iface, used)
+		}
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by gen.go from services.go; DO NOT EDIT.\n\npackage tableaumock\n\nimport (\n")
+	var names []string
+	for name := range used {
+		if name != "tableau4go" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&out, "\t%q\n", imports[name])
+	}
+	fmt.Fprintf(&out, "\n\t%q\n)\n", library_path)
+	out.Write(body.Bytes())
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("%v\n%s", err, out.Bytes())
+	}
+	if err := ioutil.WriteFile(target, formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeMock(w *bytes.Buffer, fset *token.FileSet, name string, iface *ast.InterfaceType, used map[string]bool) {
+	var embedded []string
+	var methods []*ast.Field
+	for _, field := range iface.Methods.List {
+		if len(field.Names) == 0 {
+			embedded = append(embedded, field.Type.(*ast.Ident).Name)
+		} else {
+			methods = append(methods, field)
+		}
+	}
+	fmt.Fprintf(w, "\n// %s implements tableau4go.%s", name, name)
+	if len(embedded) > 0 {
+		fmt.Fprintf(w, " by embedding the mock of each service.\n")
+	} else {
+		fmt.Fprintf(w, ". A method panics unless its Func field is set.\n")
+	}
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, service := range embedded {
+		fmt.Fprintf(w, "\t%s\n", service)
+	}
+	for _, method := range methods {
+		fmt.Fprintf(w, "\t%sFunc %s\n", method.Names[0].Name, expr(fset, qualify(method.Type, used)))
+	}
+	fmt.Fprintf(w, "}\n\nvar _ tableau4go.%s = (*%s)(nil)\n", name, name)
+	for _, method := range methods {
+		methodName := method.Names[0].Name
+		funcType := qualify(method.Type, used).(*ast.FuncType)
+		var args []string
+		for i, param := range funcType.Params.List {
+			if len(param.Names) == 0 {
+				param.Names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("p%d", i))}
+			}
+			for _, paramName := range param.Names {
+				arg := paramName.Name
+				if _, ok := param.Type.(*ast.Ellipsis); ok {
+					arg += "..."
+				}
+				args = append(args, arg)
+			}
+		}
+		signature := strings.TrimPrefix(expr(fset, funcType), "func")
+		call := fmt.Sprintf("m.%sFunc(%s)", methodName, strings.Join(args, ", "))
+		if funcType.Results != nil {
+			call = "return " + call
+		}
+		fmt.Fprintf(w, "\nfunc (m *%s) %s%s {\n", name, methodName, signature)
+		fmt.Fprintf(w, "\tif m.%sFunc == nil {\n\t\tpanic(\"tableaumock: %s.%sFunc is not set\")\n\t}\n", methodName, name, methodName)
+		fmt.Fprintf(w, "\t%s\n}\n", call)
+	}
+}
+
+// qualify prefixes the library's own types with tableau4go and notes the
+// packages the signature needs.
+func qualify(node ast.Expr, used map[string]bool) ast.Expr {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			used[n.X.(*ast.Ident).Name] = true
+			return false
+		case *ast.Field:
+			n.Type = qualifyIdent(n.Type)
+		case *ast.StarExpr:
+			n.X = qualifyIdent(n.X)
+		case *ast.ArrayType:
+			n.Elt = qualifyIdent(n.Elt)
+		case *ast.MapType:
+			n.Key = qualifyIdent(n.Key)
+			n.Value = qualifyIdent(n.Value)
+		case *ast.Ellipsis:
+			n.Elt = qualifyIdent(n.Elt)
+		}
+		return true
+	})
+	return node
+}
+
+func qualifyIdent(node ast.Expr) ast.Expr {
+	if ident, ok := node.(*ast.Ident); ok && ast.IsExported(ident.Name) {
+		return &ast.SelectorExpr{X: ast.NewIdent("tableau4go"), Sel: ident}
+	}
+	return node
+}
+
+func expr(fset *token.FileSet, node ast.Expr) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, node)
+	return b.String()
+}
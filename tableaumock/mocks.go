@@ -0,0 +1,879 @@
+// Code generated by gen.go from services.go; DO NOT EDIT.
+
+package tableaumock
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+// AuthService implements tableau4go.AuthService. A method panics unless its Func field is set.
+type AuthService struct {
+	SigninFunc       func(username, password string, contentUrl string, userIdToImpersonate string) error
+	SwitchSiteFunc   func(contentUrl string) error
+	SignoutFunc      func() error
+	AuthTokenFunc    func() string
+	SiteIDFunc       func() string
+	UserIDFunc       func() string
+	SetAuthTokenFunc func(token string, siteId string)
+}
+
+var _ tableau4go.AuthService = (*AuthService)(nil)
+
+func (m *AuthService) Signin(username, password string, contentUrl string, userIdToImpersonate string) error {
+	if m.SigninFunc == nil {
+		panic("tableaumock: AuthService.SigninFunc is not set")
+	}
+	return m.SigninFunc(username, password, contentUrl, userIdToImpersonate)
+}
+
+func (m *AuthService) SwitchSite(contentUrl string) error {
+	if m.SwitchSiteFunc == nil {
+		panic("tableaumock: AuthService.SwitchSiteFunc is not set")
+	}
+	return m.SwitchSiteFunc(contentUrl)
+}
+
+func (m *AuthService) Signout() error {
+	if m.SignoutFunc == nil {
+		panic("tableaumock: AuthService.SignoutFunc is not set")
+	}
+	return m.SignoutFunc()
+}
+
+func (m *AuthService) AuthToken() string {
+	if m.AuthTokenFunc == nil {
+		panic("tableaumock: AuthService.AuthTokenFunc is not set")
+	}
+	return m.AuthTokenFunc()
+}
+
+func (m *AuthService) SiteID() string {
+	if m.SiteIDFunc == nil {
+		panic("tableaumock: AuthService.SiteIDFunc is not set")
+	}
+	return m.SiteIDFunc()
+}
+
+func (m *AuthService) UserID() string {
+	if m.UserIDFunc == nil {
+		panic("tableaumock: AuthService.UserIDFunc is not set")
+	}
+	return m.UserIDFunc()
+}
+
+func (m *AuthService) SetAuthToken(token string, siteId string) {
+	if m.SetAuthTokenFunc == nil {
+		panic("tableaumock: AuthService.SetAuthTokenFunc is not set")
+	}
+	m.SetAuthTokenFunc(token, siteId)
+}
+
+// ServerService implements tableau4go.ServerService. A method panics unless its Func field is set.
+type ServerService struct {
+	ServerInfoFunc func() (tableau4go.ServerInfo, error)
+}
+
+var _ tableau4go.ServerService = (*ServerService)(nil)
+
+func (m *ServerService) ServerInfo() (tableau4go.ServerInfo, error) {
+	if m.ServerInfoFunc == nil {
+		panic("tableaumock: ServerService.ServerInfoFunc is not set")
+	}
+	return m.ServerInfoFunc()
+}
+
+// SiteService implements tableau4go.SiteService. A method panics unless its Func field is set.
+type SiteService struct {
+	QuerySitesFunc             func() ([]tableau4go.Site, error)
+	QuerySiteFunc              func(siteID string, includeStorage bool) (tableau4go.Site, error)
+	QuerySiteByNameFunc        func(name string, includeStorage bool) (tableau4go.Site, error)
+	QuerySiteByContentUrlFunc  func(contentUrl string, includeStorage bool) (tableau4go.Site, error)
+	GetSiteIDFunc              func(siteName string) (string, error)
+	CreateSiteFunc             func(site tableau4go.Site) (*tableau4go.Site, error)
+	UpdateSiteFunc             func(siteId string, site tableau4go.Site) (*tableau4go.Site, error)
+	DeleteSiteFunc             func(siteId string) error
+	DeleteSiteByNameFunc       func(name string) error
+	DeleteSiteByContentUrlFunc func(contentUrl string) error
+}
+
+var _ tableau4go.SiteService = (*SiteService)(nil)
+
+func (m *SiteService) QuerySites() ([]tableau4go.Site, error) {
+	if m.QuerySitesFunc == nil {
+		panic("tableaumock: SiteService.QuerySitesFunc is not set")
+	}
+	return m.QuerySitesFunc()
+}
+
+func (m *SiteService) QuerySite(siteID string, includeStorage bool) (tableau4go.Site, error) {
+	if m.QuerySiteFunc == nil {
+		panic("tableaumock: SiteService.QuerySiteFunc is not set")
+	}
+	return m.QuerySiteFunc(siteID, includeStorage)
+}
+
+func (m *SiteService) QuerySiteByName(name string, includeStorage bool) (tableau4go.Site, error) {
+	if m.QuerySiteByNameFunc == nil {
+		panic("tableaumock: SiteService.QuerySiteByNameFunc is not set")
+	}
+	return m.QuerySiteByNameFunc(name, includeStorage)
+}
+
+func (m *SiteService) QuerySiteByContentUrl(contentUrl string, includeStorage bool) (tableau4go.Site, error) {
+	if m.QuerySiteByContentUrlFunc == nil {
+		panic("tableaumock: SiteService.QuerySiteByContentUrlFunc is not set")
+	}
+	return m.QuerySiteByContentUrlFunc(contentUrl, includeStorage)
+}
+
+func (m *SiteService) GetSiteID(siteName string) (string, error) {
+	if m.GetSiteIDFunc == nil {
+		panic("tableaumock: SiteService.GetSiteIDFunc is not set")
+	}
+	return m.GetSiteIDFunc(siteName)
+}
+
+func (m *SiteService) CreateSite(site tableau4go.Site) (*tableau4go.Site, error) {
+	if m.CreateSiteFunc == nil {
+		panic("tableaumock: SiteService.CreateSiteFunc is not set")
+	}
+	return m.CreateSiteFunc(site)
+}
+
+func (m *SiteService) UpdateSite(siteId string, site tableau4go.Site) (*tableau4go.Site, error) {
+	if m.UpdateSiteFunc == nil {
+		panic("tableaumock: SiteService.UpdateSiteFunc is not set")
+	}
+	return m.UpdateSiteFunc(siteId, site)
+}
+
+func (m *SiteService) DeleteSite(siteId string) error {
+	if m.DeleteSiteFunc == nil {
+		panic("tableaumock: SiteService.DeleteSiteFunc is not set")
+	}
+	return m.DeleteSiteFunc(siteId)
+}
+
+func (m *SiteService) DeleteSiteByName(name string) error {
+	if m.DeleteSiteByNameFunc == nil {
+		panic("tableaumock: SiteService.DeleteSiteByNameFunc is not set")
+	}
+	return m.DeleteSiteByNameFunc(name)
+}
+
+func (m *SiteService) DeleteSiteByContentUrl(contentUrl string) error {
+	if m.DeleteSiteByContentUrlFunc == nil {
+		panic("tableaumock: SiteService.DeleteSiteByContentUrlFunc is not set")
+	}
+	return m.DeleteSiteByContentUrlFunc(contentUrl)
+}
+
+// UserService implements tableau4go.UserService. A method panics unless its Func field is set.
+type UserService struct {
+	QueryUserOnSiteFunc func(siteId, userId string) (tableau4go.User, error)
+}
+
+var _ tableau4go.UserService = (*UserService)(nil)
+
+func (m *UserService) QueryUserOnSite(siteId, userId string) (tableau4go.User, error) {
+	if m.QueryUserOnSiteFunc == nil {
+		panic("tableaumock: UserService.QueryUserOnSiteFunc is not set")
+	}
+	return m.QueryUserOnSiteFunc(siteId, userId)
+}
+
+// ProjectService implements tableau4go.ProjectService. A method panics unless its Func field is set.
+type ProjectService struct {
+	QueryProjectsFunc    func(siteId string) ([]tableau4go.Project, error)
+	GetProjectByNameFunc func(siteId, name string) (tableau4go.Project, error)
+	GetProjectByIDFunc   func(siteId, ID string) (tableau4go.Project, error)
+	CreateProjectFunc    func(siteId string, project tableau4go.Project) (*tableau4go.Project, error)
+	DeleteProjectFunc    func(siteId string, projectId string) error
+}
+
+var _ tableau4go.ProjectService = (*ProjectService)(nil)
+
+func (m *ProjectService) QueryProjects(siteId string) ([]tableau4go.Project, error) {
+	if m.QueryProjectsFunc == nil {
+		panic("tableaumock: ProjectService.QueryProjectsFunc is not set")
+	}
+	return m.QueryProjectsFunc(siteId)
+}
+
+func (m *ProjectService) GetProjectByName(siteId, name string) (tableau4go.Project, error) {
+	if m.GetProjectByNameFunc == nil {
+		panic("tableaumock: ProjectService.GetProjectByNameFunc is not set")
+	}
+	return m.GetProjectByNameFunc(siteId, name)
+}
+
+func (m *ProjectService) GetProjectByID(siteId, ID string) (tableau4go.Project, error) {
+	if m.GetProjectByIDFunc == nil {
+		panic("tableaumock: ProjectService.GetProjectByIDFunc is not set")
+	}
+	return m.GetProjectByIDFunc(siteId, ID)
+}
+
+func (m *ProjectService) CreateProject(siteId string, project tableau4go.Project) (*tableau4go.Project, error) {
+	if m.CreateProjectFunc == nil {
+		panic("tableaumock: ProjectService.CreateProjectFunc is not set")
+	}
+	return m.CreateProjectFunc(siteId, project)
+}
+
+func (m *ProjectService) DeleteProject(siteId string, projectId string) error {
+	if m.DeleteProjectFunc == nil {
+		panic("tableaumock: ProjectService.DeleteProjectFunc is not set")
+	}
+	return m.DeleteProjectFunc(siteId, projectId)
+}
+
+// DatasourceService implements tableau4go.DatasourceService. A method panics unless its Func field is set.
+type DatasourceService struct {
+	QueryDatasourcesFunc    func(siteId string) ([]tableau4go.Datasource, error)
+	PublishTDSFunc          func(siteId string, tdsMetadata tableau4go.Datasource, fullTds string, overwrite bool) (*tableau4go.Datasource, error)
+	CertifyDatasourceFunc   func(siteId string, datasourceId string, certificationNote string) (*tableau4go.Datasource, error)
+	UncertifyDatasourceFunc func(siteId string, datasourceId string) (*tableau4go.Datasource, error)
+	DeleteDatasourceFunc    func(siteId string, datasourceId string) error
+}
+
+var _ tableau4go.DatasourceService = (*DatasourceService)(nil)
+
+func (m *DatasourceService) QueryDatasources(siteId string) ([]tableau4go.Datasource, error) {
+	if m.QueryDatasourcesFunc == nil {
+		panic("tableaumock: DatasourceService.QueryDatasourcesFunc is not set")
+	}
+	return m.QueryDatasourcesFunc(siteId)
+}
+
+func (m *DatasourceService) PublishTDS(siteId string, tdsMetadata tableau4go.Datasource, fullTds string, overwrite bool) (*tableau4go.Datasource, error) {
+	if m.PublishTDSFunc == nil {
+		panic("tableaumock: DatasourceService.PublishTDSFunc is not set")
+	}
+	return m.PublishTDSFunc(siteId, tdsMetadata, fullTds, overwrite)
+}
+
+func (m *DatasourceService) CertifyDatasource(siteId string, datasourceId string, certificationNote string) (*tableau4go.Datasource, error) {
+	if m.CertifyDatasourceFunc == nil {
+		panic("tableaumock: DatasourceService.CertifyDatasourceFunc is not set")
+	}
+	return m.CertifyDatasourceFunc(siteId, datasourceId, certificationNote)
+}
+
+func (m *DatasourceService) UncertifyDatasource(siteId string, datasourceId string) (*tableau4go.Datasource, error) {
+	if m.UncertifyDatasourceFunc == nil {
+		panic("tableaumock: DatasourceService.UncertifyDatasourceFunc is not set")
+	}
+	return m.UncertifyDatasourceFunc(siteId, datasourceId)
+}
+
+func (m *DatasourceService) DeleteDatasource(siteId string, datasourceId string) error {
+	if m.DeleteDatasourceFunc == nil {
+		panic("tableaumock: DatasourceService.DeleteDatasourceFunc is not set")
+	}
+	return m.DeleteDatasourceFunc(siteId, datasourceId)
+}
+
+// WorkbookService implements tableau4go.WorkbookService. A method panics unless its Func field is set.
+type WorkbookService struct {
+	DownloadWorkbookPDFFunc        func(siteId, workbookId string, options tableau4go.PDFOptions, w io.Writer) error
+	DownloadWorkbookPowerPointFunc func(siteId, workbookId string, options tableau4go.PowerPointOptions, w io.Writer) error
+}
+
+var _ tableau4go.WorkbookService = (*WorkbookService)(nil)
+
+func (m *WorkbookService) DownloadWorkbookPDF(siteId, workbookId string, options tableau4go.PDFOptions, w io.Writer) error {
+	if m.DownloadWorkbookPDFFunc == nil {
+		panic("tableaumock: WorkbookService.DownloadWorkbookPDFFunc is not set")
+	}
+	return m.DownloadWorkbookPDFFunc(siteId, workbookId, options, w)
+}
+
+func (m *WorkbookService) DownloadWorkbookPowerPoint(siteId, workbookId string, options tableau4go.PowerPointOptions, w io.Writer) error {
+	if m.DownloadWorkbookPowerPointFunc == nil {
+		panic("tableaumock: WorkbookService.DownloadWorkbookPowerPointFunc is not set")
+	}
+	return m.DownloadWorkbookPowerPointFunc(siteId, workbookId, options, w)
+}
+
+// ViewService implements tableau4go.ViewService. A method panics unless its Func field is set.
+type ViewService struct {
+	QueryViewsForSiteFunc         func(siteId string, includeUsageStatistics bool) ([]tableau4go.View, error)
+	QueryViewsForWorkbookFunc     func(siteId, workbookId string, includeUsageStatistics bool) ([]tableau4go.View, error)
+	QueryViewFunc                 func(siteId, viewId string) (tableau4go.View, error)
+	QueryViewImageFunc            func(siteId, viewId string, options tableau4go.ImageOptions, w io.Writer) error
+	QueryViewPDFFunc              func(siteId, viewId string, options tableau4go.PDFOptions, w io.Writer) error
+	QueryViewDataFunc             func(siteId, viewId string, options tableau4go.DataOptions, w io.Writer) error
+	DownloadViewCrosstabExcelFunc func(siteId, viewId string, options tableau4go.DataOptions, w io.Writer) error
+}
+
+var _ tableau4go.ViewService = (*ViewService)(nil)
+
+func (m *ViewService) QueryViewsForSite(siteId string, includeUsageStatistics bool) ([]tableau4go.View, error) {
+	if m.QueryViewsForSiteFunc == nil {
+		panic("tableaumock: ViewService.QueryViewsForSiteFunc is not set")
+	}
+	return m.QueryViewsForSiteFunc(siteId, includeUsageStatistics)
+}
+
+func (m *ViewService) QueryViewsForWorkbook(siteId, workbookId string, includeUsageStatistics bool) ([]tableau4go.View, error) {
+	if m.QueryViewsForWorkbookFunc == nil {
+		panic("tableaumock: ViewService.QueryViewsForWorkbookFunc is not set")
+	}
+	return m.QueryViewsForWorkbookFunc(siteId, workbookId, includeUsageStatistics)
+}
+
+func (m *ViewService) QueryView(siteId, viewId string) (tableau4go.View, error) {
+	if m.QueryViewFunc == nil {
+		panic("tableaumock: ViewService.QueryViewFunc is not set")
+	}
+	return m.QueryViewFunc(siteId, viewId)
+}
+
+func (m *ViewService) QueryViewImage(siteId, viewId string, options tableau4go.ImageOptions, w io.Writer) error {
+	if m.QueryViewImageFunc == nil {
+		panic("tableaumock: ViewService.QueryViewImageFunc is not set")
+	}
+	return m.QueryViewImageFunc(siteId, viewId, options, w)
+}
+
+func (m *ViewService) QueryViewPDF(siteId, viewId string, options tableau4go.PDFOptions, w io.Writer) error {
+	if m.QueryViewPDFFunc == nil {
+		panic("tableaumock: ViewService.QueryViewPDFFunc is not set")
+	}
+	return m.QueryViewPDFFunc(siteId, viewId, options, w)
+}
+
+func (m *ViewService) QueryViewData(siteId, viewId string, options tableau4go.DataOptions, w io.Writer) error {
+	if m.QueryViewDataFunc == nil {
+		panic("tableaumock: ViewService.QueryViewDataFunc is not set")
+	}
+	return m.QueryViewDataFunc(siteId, viewId, options, w)
+}
+
+func (m *ViewService) DownloadViewCrosstabExcel(siteId, viewId string, options tableau4go.DataOptions, w io.Writer) error {
+	if m.DownloadViewCrosstabExcelFunc == nil {
+		panic("tableaumock: ViewService.DownloadViewCrosstabExcelFunc is not set")
+	}
+	return m.DownloadViewCrosstabExcelFunc(siteId, viewId, options, w)
+}
+
+// TagService implements tableau4go.TagService. A method panics unless its Func field is set.
+type TagService struct {
+	AddTagsToWorkbookFunc       func(siteId, workbookId string, labels ...string) ([]tableau4go.Tag, error)
+	DeleteTagFromWorkbookFunc   func(siteId, workbookId, label string) error
+	AddTagsToDatasourceFunc     func(siteId, datasourceId string, labels ...string) ([]tableau4go.Tag, error)
+	DeleteTagFromDatasourceFunc func(siteId, datasourceId, label string) error
+	AddTagsToViewFunc           func(siteId, viewId string, labels ...string) ([]tableau4go.Tag, error)
+	DeleteTagFromViewFunc       func(siteId, viewId, label string) error
+	AddTagsToFlowFunc           func(siteId, flowId string, labels ...string) ([]tableau4go.Tag, error)
+	DeleteTagFromFlowFunc       func(siteId, flowId, label string) error
+}
+
+var _ tableau4go.TagService = (*TagService)(nil)
+
+func (m *TagService) AddTagsToWorkbook(siteId, workbookId string, labels ...string) ([]tableau4go.Tag, error) {
+	if m.AddTagsToWorkbookFunc == nil {
+		panic("tableaumock: TagService.AddTagsToWorkbookFunc is not set")
+	}
+	return m.AddTagsToWorkbookFunc(siteId, workbookId, labels...)
+}
+
+func (m *TagService) DeleteTagFromWorkbook(siteId, workbookId, label string) error {
+	if m.DeleteTagFromWorkbookFunc == nil {
+		panic("tableaumock: TagService.DeleteTagFromWorkbookFunc is not set")
+	}
+	return m.DeleteTagFromWorkbookFunc(siteId, workbookId, label)
+}
+
+func (m *TagService) AddTagsToDatasource(siteId, datasourceId string, labels ...string) ([]tableau4go.Tag, error) {
+	if m.AddTagsToDatasourceFunc == nil {
+		panic("tableaumock: TagService.AddTagsToDatasourceFunc is not set")
+	}
+	return m.AddTagsToDatasourceFunc(siteId, datasourceId, labels...)
+}
+
+func (m *TagService) DeleteTagFromDatasource(siteId, datasourceId, label string) error {
+	if m.DeleteTagFromDatasourceFunc == nil {
+		panic("tableaumock: TagService.DeleteTagFromDatasourceFunc is not set")
+	}
+	return m.DeleteTagFromDatasourceFunc(siteId, datasourceId, label)
+}
+
+func (m *TagService) AddTagsToView(siteId, viewId string, labels ...string) ([]tableau4go.Tag, error) {
+	if m.AddTagsToViewFunc == nil {
+		panic("tableaumock: TagService.AddTagsToViewFunc is not set")
+	}
+	return m.AddTagsToViewFunc(siteId, viewId, labels...)
+}
+
+func (m *TagService) DeleteTagFromView(siteId, viewId, label string) error {
+	if m.DeleteTagFromViewFunc == nil {
+		panic("tableaumock: TagService.DeleteTagFromViewFunc is not set")
+	}
+	return m.DeleteTagFromViewFunc(siteId, viewId, label)
+}
+
+func (m *TagService) AddTagsToFlow(siteId, flowId string, labels ...string) ([]tableau4go.Tag, error) {
+	if m.AddTagsToFlowFunc == nil {
+		panic("tableaumock: TagService.AddTagsToFlowFunc is not set")
+	}
+	return m.AddTagsToFlowFunc(siteId, flowId, labels...)
+}
+
+func (m *TagService) DeleteTagFromFlow(siteId, flowId, label string) error {
+	if m.DeleteTagFromFlowFunc == nil {
+		panic("tableaumock: TagService.DeleteTagFromFlowFunc is not set")
+	}
+	return m.DeleteTagFromFlowFunc(siteId, flowId, label)
+}
+
+// FavoriteService implements tableau4go.FavoriteService. A method panics unless its Func field is set.
+type FavoriteService struct {
+	AddWorkbookToFavoritesFunc        func(siteId, userId, label, workbookId string) ([]tableau4go.Favorite, error)
+	AddViewToFavoritesFunc            func(siteId, userId, label, viewId string) ([]tableau4go.Favorite, error)
+	AddDatasourceToFavoritesFunc      func(siteId, userId, label, datasourceId string) ([]tableau4go.Favorite, error)
+	AddProjectToFavoritesFunc         func(siteId, userId, label, projectId string) ([]tableau4go.Favorite, error)
+	AddFlowToFavoritesFunc            func(siteId, userId, label, flowId string) ([]tableau4go.Favorite, error)
+	DeleteWorkbookFromFavoritesFunc   func(siteId, userId, workbookId string) error
+	DeleteViewFromFavoritesFunc       func(siteId, userId, viewId string) error
+	DeleteDatasourceFromFavoritesFunc func(siteId, userId, datasourceId string) error
+	DeleteProjectFromFavoritesFunc    func(siteId, userId, projectId string) error
+	DeleteFlowFromFavoritesFunc       func(siteId, userId, flowId string) error
+	QueryFavoritesForUserFunc         func(siteId, userId string) ([]tableau4go.Favorite, error)
+}
+
+var _ tableau4go.FavoriteService = (*FavoriteService)(nil)
+
+func (m *FavoriteService) AddWorkbookToFavorites(siteId, userId, label, workbookId string) ([]tableau4go.Favorite, error) {
+	if m.AddWorkbookToFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.AddWorkbookToFavoritesFunc is not set")
+	}
+	return m.AddWorkbookToFavoritesFunc(siteId, userId, label, workbookId)
+}
+
+func (m *FavoriteService) AddViewToFavorites(siteId, userId, label, viewId string) ([]tableau4go.Favorite, error) {
+	if m.AddViewToFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.AddViewToFavoritesFunc is not set")
+	}
+	return m.AddViewToFavoritesFunc(siteId, userId, label, viewId)
+}
+
+func (m *FavoriteService) AddDatasourceToFavorites(siteId, userId, label, datasourceId string) ([]tableau4go.Favorite, error) {
+	if m.AddDatasourceToFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.AddDatasourceToFavoritesFunc is not set")
+	}
+	return m.AddDatasourceToFavoritesFunc(siteId, userId, label, datasourceId)
+}
+
+func (m *FavoriteService) AddProjectToFavorites(siteId, userId, label, projectId string) ([]tableau4go.Favorite, error) {
+	if m.AddProjectToFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.AddProjectToFavoritesFunc is not set")
+	}
+	return m.AddProjectToFavoritesFunc(siteId, userId, label, projectId)
+}
+
+func (m *FavoriteService) AddFlowToFavorites(siteId, userId, label, flowId string) ([]tableau4go.Favorite, error) {
+	if m.AddFlowToFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.AddFlowToFavoritesFunc is not set")
+	}
+	return m.AddFlowToFavoritesFunc(siteId, userId, label, flowId)
+}
+
+func (m *FavoriteService) DeleteWorkbookFromFavorites(siteId, userId, workbookId string) error {
+	if m.DeleteWorkbookFromFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.DeleteWorkbookFromFavoritesFunc is not set")
+	}
+	return m.DeleteWorkbookFromFavoritesFunc(siteId, userId, workbookId)
+}
+
+func (m *FavoriteService) DeleteViewFromFavorites(siteId, userId, viewId string) error {
+	if m.DeleteViewFromFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.DeleteViewFromFavoritesFunc is not set")
+	}
+	return m.DeleteViewFromFavoritesFunc(siteId, userId, viewId)
+}
+
+func (m *FavoriteService) DeleteDatasourceFromFavorites(siteId, userId, datasourceId string) error {
+	if m.DeleteDatasourceFromFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.DeleteDatasourceFromFavoritesFunc is not set")
+	}
+	return m.DeleteDatasourceFromFavoritesFunc(siteId, userId, datasourceId)
+}
+
+func (m *FavoriteService) DeleteProjectFromFavorites(siteId, userId, projectId string) error {
+	if m.DeleteProjectFromFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.DeleteProjectFromFavoritesFunc is not set")
+	}
+	return m.DeleteProjectFromFavoritesFunc(siteId, userId, projectId)
+}
+
+func (m *FavoriteService) DeleteFlowFromFavorites(siteId, userId, flowId string) error {
+	if m.DeleteFlowFromFavoritesFunc == nil {
+		panic("tableaumock: FavoriteService.DeleteFlowFromFavoritesFunc is not set")
+	}
+	return m.DeleteFlowFromFavoritesFunc(siteId, userId, flowId)
+}
+
+func (m *FavoriteService) QueryFavoritesForUser(siteId, userId string) ([]tableau4go.Favorite, error) {
+	if m.QueryFavoritesForUserFunc == nil {
+		panic("tableaumock: FavoriteService.QueryFavoritesForUserFunc is not set")
+	}
+	return m.QueryFavoritesForUserFunc(siteId, userId)
+}
+
+// SubscriptionService implements tableau4go.SubscriptionService. A method panics unless its Func field is set.
+type SubscriptionService struct {
+	CreateSubscriptionFunc func(siteId string, subscription tableau4go.Subscription) (*tableau4go.Subscription, error)
+	UpdateSubscriptionFunc func(siteId string, subscription tableau4go.Subscription) (*tableau4go.Subscription, error)
+	QuerySubscriptionsFunc func(siteId string) ([]tableau4go.Subscription, error)
+	QuerySubscriptionFunc  func(siteId string, subscriptionId string) (tableau4go.Subscription, error)
+	DeleteSubscriptionFunc func(siteId string, subscriptionId string) error
+}
+
+var _ tableau4go.SubscriptionService = (*SubscriptionService)(nil)
+
+func (m *SubscriptionService) CreateSubscription(siteId string, subscription tableau4go.Subscription) (*tableau4go.Subscription, error) {
+	if m.CreateSubscriptionFunc == nil {
+		panic("tableaumock: SubscriptionService.CreateSubscriptionFunc is not set")
+	}
+	return m.CreateSubscriptionFunc(siteId, subscription)
+}
+
+func (m *SubscriptionService) UpdateSubscription(siteId string, subscription tableau4go.Subscription) (*tableau4go.Subscription, error) {
+	if m.UpdateSubscriptionFunc == nil {
+		panic("tableaumock: SubscriptionService.UpdateSubscriptionFunc is not set")
+	}
+	return m.UpdateSubscriptionFunc(siteId, subscription)
+}
+
+func (m *SubscriptionService) QuerySubscriptions(siteId string) ([]tableau4go.Subscription, error) {
+	if m.QuerySubscriptionsFunc == nil {
+		panic("tableaumock: SubscriptionService.QuerySubscriptionsFunc is not set")
+	}
+	return m.QuerySubscriptionsFunc(siteId)
+}
+
+func (m *SubscriptionService) QuerySubscription(siteId string, subscriptionId string) (tableau4go.Subscription, error) {
+	if m.QuerySubscriptionFunc == nil {
+		panic("tableaumock: SubscriptionService.QuerySubscriptionFunc is not set")
+	}
+	return m.QuerySubscriptionFunc(siteId, subscriptionId)
+}
+
+func (m *SubscriptionService) DeleteSubscription(siteId string, subscriptionId string) error {
+	if m.DeleteSubscriptionFunc == nil {
+		panic("tableaumock: SubscriptionService.DeleteSubscriptionFunc is not set")
+	}
+	return m.DeleteSubscriptionFunc(siteId, subscriptionId)
+}
+
+// DataAlertService implements tableau4go.DataAlertService. A method panics unless its Func field is set.
+type DataAlertService struct {
+	QueryDataAlertsFunc         func(siteId string) ([]tableau4go.DataAlert, error)
+	QueryDataAlertFunc          func(siteId string, dataAlertId string) (tableau4go.DataAlert, error)
+	DeleteDataAlertFunc         func(siteId string, dataAlertId string) error
+	AddUserToDataAlertFunc      func(siteId string, dataAlertId string, userId string) (tableau4go.User, error)
+	DeleteUserFromDataAlertFunc func(siteId string, dataAlertId string, userId string) error
+	ChangeDataAlertOwnerFunc    func(siteId string, dataAlertId string, ownerId string) (*tableau4go.DataAlert, error)
+}
+
+var _ tableau4go.DataAlertService = (*DataAlertService)(nil)
+
+func (m *DataAlertService) QueryDataAlerts(siteId string) ([]tableau4go.DataAlert, error) {
+	if m.QueryDataAlertsFunc == nil {
+		panic("tableaumock: DataAlertService.QueryDataAlertsFunc is not set")
+	}
+	return m.QueryDataAlertsFunc(siteId)
+}
+
+func (m *DataAlertService) QueryDataAlert(siteId string, dataAlertId string) (tableau4go.DataAlert, error) {
+	if m.QueryDataAlertFunc == nil {
+		panic("tableaumock: DataAlertService.QueryDataAlertFunc is not set")
+	}
+	return m.QueryDataAlertFunc(siteId, dataAlertId)
+}
+
+func (m *DataAlertService) DeleteDataAlert(siteId string, dataAlertId string) error {
+	if m.DeleteDataAlertFunc == nil {
+		panic("tableaumock: DataAlertService.DeleteDataAlertFunc is not set")
+	}
+	return m.DeleteDataAlertFunc(siteId, dataAlertId)
+}
+
+func (m *DataAlertService) AddUserToDataAlert(siteId string, dataAlertId string, userId string) (tableau4go.User, error) {
+	if m.AddUserToDataAlertFunc == nil {
+		panic("tableaumock: DataAlertService.AddUserToDataAlertFunc is not set")
+	}
+	return m.AddUserToDataAlertFunc(siteId, dataAlertId, userId)
+}
+
+func (m *DataAlertService) DeleteUserFromDataAlert(siteId string, dataAlertId string, userId string) error {
+	if m.DeleteUserFromDataAlertFunc == nil {
+		panic("tableaumock: DataAlertService.DeleteUserFromDataAlertFunc is not set")
+	}
+	return m.DeleteUserFromDataAlertFunc(siteId, dataAlertId, userId)
+}
+
+func (m *DataAlertService) ChangeDataAlertOwner(siteId string, dataAlertId string, ownerId string) (*tableau4go.DataAlert, error) {
+	if m.ChangeDataAlertOwnerFunc == nil {
+		panic("tableaumock: DataAlertService.ChangeDataAlertOwnerFunc is not set")
+	}
+	return m.ChangeDataAlertOwnerFunc(siteId, dataAlertId, ownerId)
+}
+
+// WebhookService implements tableau4go.WebhookService. A method panics unless its Func field is set.
+type WebhookService struct {
+	CreateWebhookFunc func(siteId string, webhook tableau4go.Webhook) (*tableau4go.Webhook, error)
+	QueryWebhooksFunc func(siteId string) ([]tableau4go.Webhook, error)
+	QueryWebhookFunc  func(siteId string, webhookId string) (tableau4go.Webhook, error)
+	TestWebhookFunc   func(siteId string, webhookId string) (tableau4go.WebhookTestResult, error)
+	DeleteWebhookFunc func(siteId string, webhookId string) error
+}
+
+var _ tableau4go.WebhookService = (*WebhookService)(nil)
+
+func (m *WebhookService) CreateWebhook(siteId string, webhook tableau4go.Webhook) (*tableau4go.Webhook, error) {
+	if m.CreateWebhookFunc == nil {
+		panic("tableaumock: WebhookService.CreateWebhookFunc is not set")
+	}
+	return m.CreateWebhookFunc(siteId, webhook)
+}
+
+func (m *WebhookService) QueryWebhooks(siteId string) ([]tableau4go.Webhook, error) {
+	if m.QueryWebhooksFunc == nil {
+		panic("tableaumock: WebhookService.QueryWebhooksFunc is not set")
+	}
+	return m.QueryWebhooksFunc(siteId)
+}
+
+func (m *WebhookService) QueryWebhook(siteId string, webhookId string) (tableau4go.Webhook, error) {
+	if m.QueryWebhookFunc == nil {
+		panic("tableaumock: WebhookService.QueryWebhookFunc is not set")
+	}
+	return m.QueryWebhookFunc(siteId, webhookId)
+}
+
+func (m *WebhookService) TestWebhook(siteId string, webhookId string) (tableau4go.WebhookTestResult, error) {
+	if m.TestWebhookFunc == nil {
+		panic("tableaumock: WebhookService.TestWebhookFunc is not set")
+	}
+	return m.TestWebhookFunc(siteId, webhookId)
+}
+
+func (m *WebhookService) DeleteWebhook(siteId string, webhookId string) error {
+	if m.DeleteWebhookFunc == nil {
+		panic("tableaumock: WebhookService.DeleteWebhookFunc is not set")
+	}
+	return m.DeleteWebhookFunc(siteId, webhookId)
+}
+
+// FlowService implements tableau4go.FlowService. A method panics unless its Func field is set.
+type FlowService struct {
+	QueryFlowsFunc        func(siteId string) ([]tableau4go.Flow, error)
+	QueryFlowFunc         func(siteId string, flowId string) (tableau4go.Flow, []tableau4go.FlowOutputStep, error)
+	PublishFlowFunc       func(siteId string, flowMetadata tableau4go.Flow, fileName string, flow []byte, overwrite bool) (*tableau4go.Flow, error)
+	DownloadFlowFunc      func(siteId string, flowId string, w io.Writer) error
+	DeleteFlowFunc        func(siteId string, flowId string) error
+	RunFlowNowFunc        func(siteId string, flowId string) (*tableau4go.Job, error)
+	QueryFlowRunsFunc     func(siteId string) ([]tableau4go.FlowRun, error)
+	QueryFlowRunFunc      func(siteId string, flowRunId string) (tableau4go.FlowRun, error)
+	QueryFlowRunTasksFunc func(siteId string) ([]tableau4go.FlowRunTask, error)
+	QueryFlowRunTaskFunc  func(siteId string, taskId string) (tableau4go.FlowRunTask, error)
+}
+
+var _ tableau4go.FlowService = (*FlowService)(nil)
+
+func (m *FlowService) QueryFlows(siteId string) ([]tableau4go.Flow, error) {
+	if m.QueryFlowsFunc == nil {
+		panic("tableaumock: FlowService.QueryFlowsFunc is not set")
+	}
+	return m.QueryFlowsFunc(siteId)
+}
+
+func (m *FlowService) QueryFlow(siteId string, flowId string) (tableau4go.Flow, []tableau4go.FlowOutputStep, error) {
+	if m.QueryFlowFunc == nil {
+		panic("tableaumock: FlowService.QueryFlowFunc is not set")
+	}
+	return m.QueryFlowFunc(siteId, flowId)
+}
+
+func (m *FlowService) PublishFlow(siteId string, flowMetadata tableau4go.Flow, fileName string, flow []byte, overwrite bool) (*tableau4go.Flow, error) {
+	if m.PublishFlowFunc == nil {
+		panic("tableaumock: FlowService.PublishFlowFunc is not set")
+	}
+	return m.PublishFlowFunc(siteId, flowMetadata, fileName, flow, overwrite)
+}
+
+func (m *FlowService) DownloadFlow(siteId string, flowId string, w io.Writer) error {
+	if m.DownloadFlowFunc == nil {
+		panic("tableaumock: FlowService.DownloadFlowFunc is not set")
+	}
+	return m.DownloadFlowFunc(siteId, flowId, w)
+}
+
+func (m *FlowService) DeleteFlow(siteId string, flowId string) error {
+	if m.DeleteFlowFunc == nil {
+		panic("tableaumock: FlowService.DeleteFlowFunc is not set")
+	}
+	return m.DeleteFlowFunc(siteId, flowId)
+}
+
+func (m *FlowService) RunFlowNow(siteId string, flowId string) (*tableau4go.Job, error) {
+	if m.RunFlowNowFunc == nil {
+		panic("tableaumock: FlowService.RunFlowNowFunc is not set")
+	}
+	return m.RunFlowNowFunc(siteId, flowId)
+}
+
+func (m *FlowService) QueryFlowRuns(siteId string) ([]tableau4go.FlowRun, error) {
+	if m.QueryFlowRunsFunc == nil {
+		panic("tableaumock: FlowService.QueryFlowRunsFunc is not set")
+	}
+	return m.QueryFlowRunsFunc(siteId)
+}
+
+func (m *FlowService) QueryFlowRun(siteId string, flowRunId string) (tableau4go.FlowRun, error) {
+	if m.QueryFlowRunFunc == nil {
+		panic("tableaumock: FlowService.QueryFlowRunFunc is not set")
+	}
+	return m.QueryFlowRunFunc(siteId, flowRunId)
+}
+
+func (m *FlowService) QueryFlowRunTasks(siteId string) ([]tableau4go.FlowRunTask, error) {
+	if m.QueryFlowRunTasksFunc == nil {
+		panic("tableaumock: FlowService.QueryFlowRunTasksFunc is not set")
+	}
+	return m.QueryFlowRunTasksFunc(siteId)
+}
+
+func (m *FlowService) QueryFlowRunTask(siteId string, taskId string) (tableau4go.FlowRunTask, error) {
+	if m.QueryFlowRunTaskFunc == nil {
+		panic("tableaumock: FlowService.QueryFlowRunTaskFunc is not set")
+	}
+	return m.QueryFlowRunTaskFunc(siteId, taskId)
+}
+
+// JobService implements tableau4go.JobService. A method panics unless its Func field is set.
+type JobService struct {
+	QueryJobFunc func(siteId string, jobId string) (tableau4go.Job, error)
+}
+
+var _ tableau4go.JobService = (*JobService)(nil)
+
+func (m *JobService) QueryJob(siteId string, jobId string) (tableau4go.Job, error) {
+	if m.QueryJobFunc == nil {
+		panic("tableaumock: JobService.QueryJobFunc is not set")
+	}
+	return m.QueryJobFunc(siteId, jobId)
+}
+
+// MetadataService implements tableau4go.MetadataService. A method panics unless its Func field is set.
+type MetadataService struct {
+	MetadataQueryRawFunc       func(query string, variables map[string]interface{}) (json.RawMessage, error)
+	MetadataQueryFunc          func(query string, variables map[string]interface{}, result interface{}) error
+	MetadataQueryAllFunc       func(query string, variables map[string]interface{}, connection string, fn func(nodes json.RawMessage) error) error
+	QueryDatasourceLineageFunc func(datasourceId string) (tableau4go.MetadataDatasource, error)
+	QueryWorkbookLineageFunc   func(workbookId string) (tableau4go.MetadataWorkbook, error)
+	QueryTableLineageFunc      func(database, schema, table string) ([]tableau4go.MetadataTable, error)
+	TableImpactFunc            func(database, schema, table string) (*tableau4go.LineageGraph, error)
+}
+
+var _ tableau4go.MetadataService = (*MetadataService)(nil)
+
+func (m *MetadataService) MetadataQueryRaw(query string, variables map[string]interface{}) (json.RawMessage, error) {
+	if m.MetadataQueryRawFunc == nil {
+		panic("tableaumock: MetadataService.MetadataQueryRawFunc is not set")
+	}
+	return m.MetadataQueryRawFunc(query, variables)
+}
+
+func (m *MetadataService) MetadataQuery(query string, variables map[string]interface{}, result interface{}) error {
+	if m.MetadataQueryFunc == nil {
+		panic("tableaumock: MetadataService.MetadataQueryFunc is not set")
+	}
+	return m.MetadataQueryFunc(query, variables, result)
+}
+
+func (m *MetadataService) MetadataQueryAll(query string, variables map[string]interface{}, connection string, fn func(nodes json.RawMessage) error) error {
+	if m.MetadataQueryAllFunc == nil {
+		panic("tableaumock: MetadataService.MetadataQueryAllFunc is not set")
+	}
+	return m.MetadataQueryAllFunc(query, variables, connection, fn)
+}
+
+func (m *MetadataService) QueryDatasourceLineage(datasourceId string) (tableau4go.MetadataDatasource, error) {
+	if m.QueryDatasourceLineageFunc == nil {
+		panic("tableaumock: MetadataService.QueryDatasourceLineageFunc is not set")
+	}
+	return m.QueryDatasourceLineageFunc(datasourceId)
+}
+
+func (m *MetadataService) QueryWorkbookLineage(workbookId string) (tableau4go.MetadataWorkbook, error) {
+	if m.QueryWorkbookLineageFunc == nil {
+		panic("tableaumock: MetadataService.QueryWorkbookLineageFunc is not set")
+	}
+	return m.QueryWorkbookLineageFunc(workbookId)
+}
+
+func (m *MetadataService) QueryTableLineage(database, schema, table string) ([]tableau4go.MetadataTable, error) {
+	if m.QueryTableLineageFunc == nil {
+		panic("tableaumock: MetadataService.QueryTableLineageFunc is not set")
+	}
+	return m.QueryTableLineageFunc(database, schema, table)
+}
+
+func (m *MetadataService) TableImpact(database, schema, table string) (*tableau4go.LineageGraph, error) {
+	if m.TableImpactFunc == nil {
+		panic("tableaumock: MetadataService.TableImpactFunc is not set")
+	}
+	return m.TableImpactFunc(database, schema, table)
+}
+
+// VizQLService implements tableau4go.VizQLService. A method panics unless its Func field is set.
+type VizQLService struct {
+	VizQLReadMetadataFunc    func(datasourceId string) ([]tableau4go.VizQLFieldMetadata, error)
+	VizQLQueryDatasourceFunc func(datasourceId string, query *tableau4go.VizQLQuery) (*tableau4go.VizQLRows, error)
+}
+
+var _ tableau4go.VizQLService = (*VizQLService)(nil)
+
+func (m *VizQLService) VizQLReadMetadata(datasourceId string) ([]tableau4go.VizQLFieldMetadata, error) {
+	if m.VizQLReadMetadataFunc == nil {
+		panic("tableaumock: VizQLService.VizQLReadMetadataFunc is not set")
+	}
+	return m.VizQLReadMetadataFunc(datasourceId)
+}
+
+func (m *VizQLService) VizQLQueryDatasource(datasourceId string, query *tableau4go.VizQLQuery) (*tableau4go.VizQLRows, error) {
+	if m.VizQLQueryDatasourceFunc == nil {
+		panic("tableaumock: VizQLService.VizQLQueryDatasourceFunc is not set")
+	}
+	return m.VizQLQueryDatasourceFunc(datasourceId, query)
+}
+
+// Client implements tableau4go.Client by embedding the mock of each service.
+type Client struct {
+	AuthService
+	ServerService
+	SiteService
+	UserService
+	ProjectService
+	DatasourceService
+	WorkbookService
+	ViewService
+	TagService
+	FavoriteService
+	SubscriptionService
+	DataAlertService
+	WebhookService
+	FlowService
+	JobService
+	MetadataService
+	VizQLService
+}
+
+var _ tableau4go.Client = (*Client)(nil)
@@ -0,0 +1,165 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tableauotel reports tableau4go calls to OpenTelemetry: a client span
+// per call named after the operation (tableau.QueryProjects), plus a request
+// counter and a latency histogram. It uses the global providers unless told
+// otherwise, so it stays a no-op until the application configures them.
+//
+//	api.Observer, err = tableauotel.New()
+//	...
+//	api.HTTPClient = &http.Client{Transport: tableauotel.NewTransport(nil)}
+//	projects, err := api.WithContext(ctx).QueryProjects(siteId)
+//
+// The call's span rides on the request context, NewTransport adds the
+// traceparent header from it so the trace carries on into Tableau.
+//
+// tableauotel is a module of its own, so only programs that import it depend
+// on OpenTelemetry.
+package tableauotel
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/mattbaird/tableau4go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentation_name = "github.com/mattbaird/tableau4go"
+
+const (
+	OperationKey  = attribute.Key("tableau.operation")
+	SiteIDKey     = attribute.Key("tableau.site_id")
+	ErrorCodeKey  = attribute.Key("tableau.error_code")
+	MethodKey     = attribute.Key("http.request.method")
+	URLKey        = attribute.Key("url.full")
+	StatusCodeKey = attribute.Key("http.response.status_code")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+type Option func(*config)
+
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Observer implements tableau4go.Observer.
+type Observer struct {
+	tracer   trace.Tracer
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+var _ tableau4go.Observer = (*Observer)(nil)
+
+func New(options ...Option) (*Observer, error) {
+	c := config{tracerProvider: otel.GetTracerProvider(), meterProvider: otel.GetMeterProvider()}
+	for _, option := range options {
+		option(&c)
+	}
+	meter := c.meterProvider.Meter(instrumentation_name)
+	requests, err := meter.Int64Counter("tableau.client.requests",
+		metric.WithDescription("Tableau API calls made, retries included in one call"),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("tableau.client.request.duration",
+		metric.WithDescription("Duration of Tableau API calls, retries included"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &Observer{tracer: c.tracerProvider.Tracer(instrumentation_name), requests: requests, duration: duration}, nil
+}
+
+func (o *Observer) StartCall(ctx context.Context, call tableau4go.CallInfo) (context.Context, func(tableau4go.CallResult)) {
+	ctx, span := o.tracer.Start(ctx, "tableau."+call.Operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			OperationKey.String(call.Operation),
+			MethodKey.String(call.Method),
+			URLKey.String(call.URL),
+			SiteIDKey.String(call.SiteID),
+		))
+	return ctx, func(result tableau4go.CallResult) {
+		// site and url are left off the metrics to keep their cardinality down
+		attrs := []attribute.KeyValue{
+			OperationKey.String(call.Operation),
+			MethodKey.String(call.Method),
+		}
+		if result.StatusCode > 0 {
+			attrs = append(attrs, StatusCodeKey.Int(result.StatusCode))
+		}
+		if len(result.ErrorCode) > 0 {
+			attrs = append(attrs, ErrorCodeKey.String(result.ErrorCode))
+		}
+		span.SetAttributes(attrs[2:]...)
+		if result.Err != nil {
+			span.RecordError(result.Err)
+			span.SetStatus(codes.Error, errorDescription(result))
+		}
+		span.End()
+		set := metric.WithAttributes(attrs...)
+		o.requests.Add(ctx, 1, set)
+		o.duration.Record(ctx, result.Duration.Seconds(), set)
+	}
+}
+
+func errorDescription(result tableau4go.CallResult) string {
+	if len(result.ErrorCode) > 0 {
+		return "tableau error " + result.ErrorCode
+	}
+	if result.StatusCode > 0 {
+		return "http status " + strconv.Itoa(result.StatusCode)
+	}
+	return result.Err.Error()
+}
+
+// Transport injects the trace context of each request into its headers, using
+// the global propagator, before handing it to Base.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base, http.DefaultTransport when nil.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return t.Base.RoundTrip(req)
+}
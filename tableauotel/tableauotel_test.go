@@ -0,0 +1,153 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableauotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+	"github.com/mattbaird/tableau4go/tableautest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTransportInjectsTraceparent(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: NewTransport(nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"; traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+	if len(req.Header.Get("traceparent")) > 0 {
+		t.Error("the caller's request was modified")
+	}
+}
+
+func TestObserverSpansAndMetrics(t *testing.T) {
+	server := tableautest.NewServer()
+	defer server.Close()
+	server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin"}, "secret")
+	api := server.API()
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	observer, err := New(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.Observer = observer
+	siteID := api.SiteID()
+	if _, err := api.QueryProjects(siteID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.CreateProject(siteID, tableau4go.Project{Name: tableautest.DEFAULT_PROJECT_NAME}); err == nil {
+		t.Fatal("creating the default project again succeeded")
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("%d spans, want 2", len(ended))
+	}
+	for i, want := range []struct {
+		name      string
+		status    int64
+		errorCode string
+		code      codes.Code
+	}{
+		{"tableau.QueryProjects", http.StatusOK, "", codes.Unset},
+		{"tableau.CreateProject", http.StatusConflict, tableautest.ERROR_PROJECT_CONFLICT, codes.Error},
+	} {
+		span := ended[i]
+		if span.Name() != want.name || span.SpanKind() != trace.SpanKindClient || span.Status().Code != want.code {
+			t.Errorf("span %d = %s (%s, %v), want %s", i, span.Name(), span.SpanKind(), span.Status(), want.name)
+		}
+		attrs := attribute.NewSet(span.Attributes()...)
+		if v, _ := attrs.Value(SiteIDKey); v.AsString() != siteID {
+			t.Errorf("%s: site = %q, want %q", want.name, v.AsString(), siteID)
+		}
+		if v, _ := attrs.Value(StatusCodeKey); v.AsInt64() != want.status {
+			t.Errorf("%s: status = %d, want %d", want.name, v.AsInt64(), want.status)
+		}
+		if v, ok := attrs.Value(ErrorCodeKey); v.AsString() != want.errorCode || ok != (len(want.errorCode) > 0) {
+			t.Errorf("%s: error code = %q, want %q", want.name, v.AsString(), want.errorCode)
+		}
+	}
+
+	var collected metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &collected); err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]metricdata.Aggregation{}
+	for _, scope := range collected.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			found[m.Name] = m.Data
+		}
+	}
+	requests, ok := found["tableau.client.requests"].(metricdata.Sum[int64])
+	if !ok || len(requests.DataPoints) != 2 {
+		t.Fatalf("tableau.client.requests = %+v", found["tableau.client.requests"])
+	}
+	for _, point := range requests.DataPoints {
+		operation, _ := point.Attributes.Value(OperationKey)
+		if point.Value != 1 || point.Attributes.HasValue(SiteIDKey) || point.Attributes.HasValue(URLKey) {
+			t.Errorf("%s: %d requests with %v", operation.AsString(), point.Value, point.Attributes.ToSlice())
+		}
+		if operation.AsString() == "CreateProject" {
+			if code, _ := point.Attributes.Value(ErrorCodeKey); code.AsString() != tableautest.ERROR_PROJECT_CONFLICT {
+				t.Errorf("CreateProject counted with %v", point.Attributes.ToSlice())
+			}
+		}
+	}
+	duration, ok := found["tableau.client.request.duration"].(metricdata.Histogram[float64])
+	if !ok || len(duration.DataPoints) != 2 {
+		t.Fatalf("tableau.client.request.duration = %+v", found["tableau.client.request.duration"])
+	}
+	for _, point := range duration.DataPoints {
+		if point.Count != 1 || point.Sum <= 0 {
+			t.Errorf("latency %v: count %d, sum %v", point.Attributes.ToSlice(), point.Count, point.Sum)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableautest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+const redacted = "REDACTED"
+
+type CassetteMode string
+
+const (
+	// RECORD sends requests on to the server and writes every exchange to the cassette
+	RECORD CassetteMode = "record"
+	// REPLAY answers requests from the cassette and never touches the network
+	REPLAY CassetteMode = "replay"
+)
+
+// headers that carry credentials, kept in the cassette but blanked out
+var secretHeaders = []string{"X-Tableau-Auth", "Authorization", "Cookie", "Set-Cookie"}
+
+// Cassette is an http.RoundTripper that records Tableau traffic to a file, or
+// replays it from one. Record once against a real server, commit the file and
+// replay it in CI:
+//
+//	cassette, err := tableautest.NewCassette("testdata/publish.json", tableautest.REPLAY, nil)
+//	...
+//	api.HTTPClient = cassette.Client()
+//
+// Auth tokens, cookies, passwords and personal access token secrets are scrubbed
+// from headers and text bodies before anything is written, binary bodies are
+// kept exactly as they were sent. A request is replayed by matching its method, path
+// and query, requests matching more than one recording get them in the order
+// they were recorded.
+type Cassette struct {
+	mode         CassetteMode
+	path         string
+	transport    http.RoundTripper
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// Interaction is one recorded request and the response it got.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	RecordedBody
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	RecordedBody
+}
+
+// RecordedBody keeps text as it is so cassettes stay readable and diffable, and
+// anything else (images, packaged workbooks) as base64.
+type RecordedBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 bool   `json:"bodyBase64,omitempty"`
+}
+
+// NewCassette opens the cassette at path. Recording starts it afresh and sends
+// requests through transport, or the transport of DefaultTimeoutClient when nil.
+// Replaying needs the file to exist and ignores transport.
+func NewCassette(path string, mode CassetteMode, transport http.RoundTripper) (*Cassette, error) {
+	cassette := &Cassette{mode: mode, path: path, transport: transport}
+	switch mode {
+	case RECORD:
+		if cassette.transport == nil {
+			cassette.transport = tableau4go.DefaultTimeoutClient().Transport
+		}
+		return cassette, cassette.save()
+	case REPLAY:
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(content, &cassette.interactions); err != nil {
+			return nil, fmt.Errorf("tableautest: reading cassette %s: %w", path, err)
+		}
+		cassette.replayed = make([]bool, len(cassette.interactions))
+		return cassette, nil
+	}
+	return nil, fmt.Errorf("tableautest: unknown cassette mode %q", mode)
+}
+
+// Client returns an http.Client going through the cassette, for API.HTTPClient.
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.mode == REPLAY {
+		return c.replay(req)
+	}
+	return c.record(req, body)
+}
+
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := c.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// the caller gets the real thing, only the cassette is scrubbed
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:       req.Method,
+			Path:         req.URL.Path,
+			Query:        canonicalQuery(req.URL.RawQuery),
+			Header:       scrubHeaders(req.Header),
+			RecordedBody: recordBody(req.Header, body),
+		},
+		Response: RecordedResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       scrubHeaders(resp.Header),
+			RecordedBody: recordBody(resp.Header, respBody),
+		},
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction)
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	query := canonicalQuery(req.URL.RawQuery)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, interaction := range c.interactions {
+		recorded := interaction.Request
+		if c.replayed[i] || recorded.Method != req.Method || recorded.Path != req.URL.Path || recorded.Query != query {
+			continue
+		}
+		c.replayed[i] = true
+		body, err := interaction.Response.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("tableautest: no recording of %s %s left in cassette %s", req.Method, req.URL.RequestURI(), c.path)
+}
+
+// save rewrites the cassette, after every exchange so a test that dies halfway
+// still leaves what it recorded.
+func (c *Cassette) save() error {
+	interactions := c.interactions
+	if interactions == nil {
+		interactions = []Interaction{}
+	}
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	// the bodies are xml, keep them legible
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(interactions); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, content.Bytes(), 0644)
+}
+
+// canonicalQuery sorts the parameters so the order a client builds them in
+// doesn't matter.
+func canonicalQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return rawQuery
+	}
+	return values.Encode()
+}
+
+func scrubHeaders(header http.Header) http.Header {
+	retval := header.Clone()
+	for _, name := range secretHeaders {
+		if len(retval.Values(name)) > 0 {
+			retval.Set(name, redacted)
+		}
+	}
+	return retval
+}
+
+func recordBody(header http.Header, body []byte) RecordedBody {
+	if len(body) == 0 {
+		return RecordedBody{}
+	}
+	body = scrubBody(header.Get("Content-Type"), body)
+	if !utf8.Valid(body) {
+		return RecordedBody{Body: base64.StdEncoding.EncodeToString(body), BodyBase64: true}
+	}
+	return RecordedBody{Body: string(body)}
+}
+
+// scrubBody redacts secrets from text and from the text parts of a multipart
+// body, a publish carries its credentials in the xml part next to the file.
+// Anything else is left byte for byte as it is replayed.
+func scrubBody(contentType string, body []byte) []byte {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		// no content type to go on, text is what's worth scrubbing
+		if utf8.Valid(body) {
+			return tableau4go.Redact(body)
+		}
+		return body
+	}
+	if strings.HasPrefix(mediaType, "multipart/") && len(params["boundary"]) > 0 {
+		return scrubParts(body, params["boundary"])
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "xml") || strings.HasSuffix(mediaType, "json") {
+		return tableau4go.Redact(body)
+	}
+	return body
+}
+
+// scrubParts splits the raw body on the boundary rather than going through
+// mime/multipart, so delimiters, part headers and binary parts stay untouched.
+func scrubParts(body []byte, boundary string) []byte {
+	delimiter := []byte("--" + boundary)
+	parts := bytes.Split(body, delimiter)
+	for i, part := range parts {
+		end := bytes.Index(part, []byte("\r\n\r\n"))
+		if end < 0 {
+			continue
+		}
+		contentType := ""
+		for _, line := range strings.Split(string(part[:end]), "\r\n") {
+			name, value, found := strings.Cut(line, ":")
+			if found && strings.EqualFold(strings.TrimSpace(name), "Content-Type") {
+				contentType = strings.TrimSpace(value)
+			}
+		}
+		scrubbed := append([]byte{}, part[:end+4]...)
+		parts[i] = append(scrubbed, scrubBody(contentType, part[end+4:])...)
+	}
+	return bytes.Join(parts, delimiter)
+}
+
+func (b RecordedBody) bytes() ([]byte, error) {
+	if b.BodyBase64 {
+		return base64.StdEncoding.DecodeString(b.Body)
+	}
+	return []byte(b.Body), nil
+}
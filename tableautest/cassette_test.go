@@ -0,0 +1,206 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableautest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+func readCassette(t *testing.T, path string) (string, []Interaction) {
+	t.Helper()
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(content, &interactions); err != nil {
+		t.Fatal(err)
+	}
+	return string(content), interactions
+}
+
+func TestCassetteScrubsSecrets(t *testing.T) {
+	// the quote ends the password early for a careless json pattern
+	const password = `hunter2"s3cret-tail`
+	for _, format := range []string{tableau4go.FORMAT_XML, tableau4go.FORMAT_JSON} {
+		server := NewServer()
+		defer server.Close()
+		server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin"}, password)
+		path := filepath.Join(t.TempDir(), "scrub.json")
+		cassette, err := NewCassette(path, RECORD, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		api := server.API()
+		api.Format = format
+		api.HTTPClient = cassette.Client()
+		if err := api.Signin("admin", password, "", ""); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		token := api.AuthToken()
+		credentials := tableau4go.NewConnectionCredentials("dbuser", "dbpass-s3cret", true)
+		_, err = api.PublishTDS(api.SiteID(), tableau4go.Datasource{Name: "Sales", ConnectionCredentials: &credentials}, "<datasource/>", false)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if _, err := api.QueryProjects(api.SiteID()); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		content, interactions := readCassette(t, path)
+		for _, secret := range []string{"hunter2", "s3cret-tail", "dbpass-s3cret", token} {
+			if strings.Contains(content, secret) {
+				t.Errorf("%s: cassette contains %q:\n%s", format, secret, content)
+			}
+		}
+		if len(interactions) != 3 {
+			t.Fatalf("%s: %d interactions recorded, want 3", format, len(interactions))
+		}
+		for _, interaction := range interactions[1:] {
+			if got := interaction.Request.Header.Get("X-Tableau-Auth"); got != redacted {
+				t.Errorf("%s: %s %s: X-Tableau-Auth = %q", format, interaction.Request.Method, interaction.Request.Path, got)
+			}
+		}
+		// the caller still got the real token
+		if len(token) == 0 || token == redacted {
+			t.Errorf("%s: token = %q", format, token)
+		}
+	}
+}
+
+func TestScrubBodyLeavesBinaryAlone(t *testing.T) {
+	// binary that happens to look like a secret must replay as it was recorded
+	binary := []byte("\x89PNG\r\n\x1a\n password=\"keep\" \x00\xff")
+	if got := scrubBody("image/png", binary); !bytes.Equal(got, binary) {
+		t.Errorf("image body changed to %q", got)
+	}
+	payload := "--b0und\r\n" +
+		"Content-Disposition: name=\"request_payload\"\r\n" +
+		"Content-Type: text/xml\r\n\r\n" +
+		`<tsRequest><connectionCredentials name="dbuser" password="dbpass"/></tsRequest>` + "\r\n" +
+		"--b0und\r\n" +
+		"Content-Disposition: name=\"tableau_datasource\"; filename=\"sales.tdsx\"\r\n" +
+		"Content-Type: application/octet-stream\r\n\r\n" +
+		string(binary) + "\r\n" +
+		"--b0und--\r\n"
+	want := strings.Replace(payload, `password="dbpass"`, `password="`+redacted+`"`, 1)
+	if got := string(scrubBody("multipart/mixed; boundary=b0und", []byte(payload))); got != want {
+		t.Errorf("multipart scrubbed to\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCassetteBinaryBodies(t *testing.T) {
+	image := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0xff, 0xfe}
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(image)
+	}))
+	path := filepath.Join(t.TempDir(), "binary.json")
+	recorder, err := NewCassette(path, RECORD, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := recorder.Client().Post(origin.URL+"/image", "application/octet-stream", bytes.NewReader(image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(body, image) {
+		t.Fatalf("recording handed back %x", body)
+	}
+	origin.Close()
+	_, interactions := readCassette(t, path)
+	if !interactions[0].Request.BodyBase64 || !interactions[0].Response.BodyBase64 {
+		t.Errorf("binary bodies not stored as base64: %+v", interactions[0])
+	}
+	player, err := NewCassette(path, REPLAY, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = player.Client().Post(origin.URL+"/image", "application/octet-stream", bytes.NewReader(image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(body, image) || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("replayed %x (%s), want %x", body, resp.Header.Get("Content-Type"), image)
+	}
+}
+
+func TestCassetteReplaysInOrder(t *testing.T) {
+	server := NewServer()
+	server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin"}, "secret")
+	path := filepath.Join(t.TempDir(), "order.json")
+	recorder, err := NewCassette(path, RECORD, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := server.API()
+	api.HTTPClient = recorder.Client()
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	siteID := api.SiteID()
+	for _, name := range []string{"First", "Second"} {
+		if _, err := api.CreateProject(siteID, tableau4go.Project{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := api.QueryProjects(siteID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.Close()
+
+	player, err := NewCassette(path, REPLAY, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api = server.API()
+	api.HTTPClient = player.Client()
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	// the same GET twice gets the two recordings in turn
+	for _, want := range []int{2, 3} {
+		projects, err := api.QueryProjects(siteID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(projects) != want {
+			t.Errorf("replayed %d projects, want %d", len(projects), want)
+		}
+	}
+	_, err = api.QueryProjects(siteID)
+	if err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Errorf("a third QueryProjects: err = %v, want the cassette to be used up", err)
+	}
+	// a different query is a different request
+	if _, err := api.QuerySite(siteID, true); err == nil {
+		t.Error("replayed a request that was never recorded")
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	if a, b := canonicalQuery("b=2&a=1"), canonicalQuery("a=1&b=2"); a != b {
+		t.Errorf("%q != %q", a, b)
+	}
+}
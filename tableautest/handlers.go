@@ -0,0 +1,835 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableautest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+const auth_header = "X-Tableau-Auth"
+const tableau_namespace = "http://tableau.com/api"
+const default_page_size = 100
+const json_content_type = "application/json"
+
+// tsResponse is every response document, only the parts a call sets are written.
+type tsResponse struct {
+	XMLName     struct{}                `json:"-" xml:"tsResponse"`
+	Xmlns       string                  `json:"-" xml:"xmlns,attr"`
+	Pagination  *pagination             `json:"pagination,omitempty" xml:"pagination,omitempty"`
+	Error       *tableau4go.Terror      `json:"error,omitempty" xml:"error,omitempty"`
+	Credentials *tableau4go.Credentials `json:"credentials,omitempty" xml:"credentials,omitempty"`
+	ServerInfo  *tableau4go.ServerInfo  `json:"serverInfo,omitempty" xml:"serverInfo,omitempty"`
+	Site        *tableau4go.Site        `json:"site,omitempty" xml:"site,omitempty"`
+	Sites       *tableau4go.Sites       `json:"sites,omitempty" xml:"sites,omitempty"`
+	Project     *tableau4go.Project     `json:"project,omitempty" xml:"project,omitempty"`
+	Projects    *tableau4go.Projects    `json:"projects,omitempty" xml:"projects,omitempty"`
+	Datasource  *tableau4go.Datasource  `json:"datasource,omitempty" xml:"datasource,omitempty"`
+	Datasources *tableau4go.Datasources `json:"datasources,omitempty" xml:"datasources,omitempty"`
+	User        *tableau4go.User        `json:"user,omitempty" xml:"user,omitempty"`
+	Users       *users                  `json:"users,omitempty" xml:"users,omitempty"`
+}
+
+type users struct {
+	Users []tableau4go.User `json:"user,omitempty" xml:"user,omitempty"`
+}
+
+type pagination struct {
+	PageNumber     int `json:"pageNumber,string" xml:"pageNumber,attr"`
+	PageSize       int `json:"pageSize,string" xml:"pageSize,attr"`
+	TotalAvailable int `json:"totalAvailable,string" xml:"totalAvailable,attr"`
+}
+
+// datasourceUpdate is the body of Update Data Source, where anything left out
+// stays as it is.
+type datasourceUpdate struct {
+	Datasource struct {
+		Name              string              `json:"name,omitempty" xml:"name,attr,omitempty"`
+		IsCertified       *bool               `json:"isCertified,omitempty" xml:"isCertified,attr,omitempty"`
+		CertificationNote *string             `json:"certificationNote,omitempty" xml:"certificationNote,attr,omitempty"`
+		Project           *tableau4go.Project `json:"project,omitempty" xml:"project,omitempty"`
+		Owner             *tableau4go.User    `json:"owner,omitempty" xml:"owner,omitempty"`
+	} `json:"datasource" xml:"datasource"`
+}
+
+// call is a request on its way through a route. ids holds the {} path segments,
+// site the site of a sites/{}/... route.
+type call struct {
+	r       *http.Request
+	token   string
+	session authSession
+	ids     []string
+	site    *site
+}
+
+type route struct {
+	method  string
+	pattern string
+	handle  func(c *call) (int, *tsResponse)
+	public  bool
+}
+
+func (s *Server) routes() []route {
+	return []route{
+		{method: tableau4go.POST, pattern: "auth/signin", handle: s.signin, public: true},
+		{method: tableau4go.POST, pattern: "auth/switchSite", handle: s.switchSite},
+		{method: tableau4go.POST, pattern: "auth/signout", handle: s.signout},
+		{method: tableau4go.GET, pattern: "serverinfo", handle: s.serverInfo, public: true},
+		{method: tableau4go.GET, pattern: "sites", handle: s.querySites},
+		{method: tableau4go.POST, pattern: "sites", handle: s.createSite},
+		{method: tableau4go.GET, pattern: "sites/{}", handle: s.querySite},
+		{method: tableau4go.PUT, pattern: "sites/{}", handle: s.updateSite},
+		{method: tableau4go.DELETE, pattern: "sites/{}", handle: s.deleteSite},
+		{method: tableau4go.GET, pattern: "sites/{}/projects", handle: s.queryProjects},
+		{method: tableau4go.POST, pattern: "sites/{}/projects", handle: s.createProject},
+		{method: tableau4go.PUT, pattern: "sites/{}/projects/{}", handle: s.updateProject},
+		{method: tableau4go.DELETE, pattern: "sites/{}/projects/{}", handle: s.deleteProject},
+		{method: tableau4go.GET, pattern: "sites/{}/datasources", handle: s.queryDatasources},
+		{method: tableau4go.POST, pattern: "sites/{}/datasources", handle: s.publishDatasource},
+		{method: tableau4go.GET, pattern: "sites/{}/datasources/{}", handle: s.queryDatasource},
+		{method: tableau4go.PUT, pattern: "sites/{}/datasources/{}", handle: s.updateDatasource},
+		{method: tableau4go.DELETE, pattern: "sites/{}/datasources/{}", handle: s.deleteDatasource},
+		{method: tableau4go.GET, pattern: "sites/{}/users", handle: s.queryUsers},
+		{method: tableau4go.POST, pattern: "sites/{}/users", handle: s.addUser},
+		{method: tableau4go.GET, pattern: "sites/{}/users/{}", handle: s.queryUser},
+		{method: tableau4go.DELETE, pattern: "sites/{}/users/{}", handle: s.removeUser},
+	}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	statusCode, response := s.route(r)
+	s.mu.Unlock()
+	write(w, r, statusCode, response)
+}
+
+// route matches /api/{version}/... against routes, any version is accepted.
+func (s *Server) route(r *http.Request) (int, *tsResponse) {
+	if len(s.failures) > 0 {
+		next := s.failures[0]
+		s.failures = s.failures[1:]
+		return next.statusCode, &tsResponse{Error: &next.err}
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 3 || segments[0] != "api" {
+		return notFound(r)
+	}
+	segments = segments[2:]
+	pathMatched := false
+	for _, rt := range s.routes() {
+		ids, ok := match(rt.pattern, segments)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if rt.method != r.Method {
+			continue
+		}
+		c := &call{r: r, ids: ids}
+		if !rt.public {
+			session, ok := s.tokens[r.Header.Get(auth_header)]
+			if !ok {
+				return unauthorized()
+			}
+			c.token = r.Header.Get(auth_header)
+			c.session = session
+		}
+		if strings.HasPrefix(rt.pattern, "sites/{}/") {
+			// a token is only good for the site it was issued for
+			if ids[0] != c.session.siteID {
+				return unauthorized()
+			}
+			c.site = s.site(ids[0])
+			if c.site == nil {
+				return siteNotFound(ids[0])
+			}
+		}
+		return rt.handle(c)
+	}
+	if pathMatched {
+		return fail(http.StatusMethodNotAllowed, ERROR_METHOD_NOT_ALLOWED, "Method Not Allowed", fmt.Sprintf("Request type '%s' is not supported for this resource.", r.Method))
+	}
+	return notFound(r)
+}
+
+func match(pattern string, segments []string) ([]string, bool) {
+	parts := strings.Split(pattern, "/")
+	if len(parts) != len(segments) {
+		return nil, false
+	}
+	var ids []string
+	for i, part := range parts {
+		switch {
+		case part == "{}":
+			ids = append(ids, segments[i])
+		case part != segments[i]:
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// write answers in json when the client accepts it, like the real server, and
+// in xml otherwise.
+func write(w http.ResponseWriter, r *http.Request, statusCode int, response *tsResponse) {
+	if response == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), json_content_type) {
+		body, err := json.Marshal(response)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		w.WriteHeader(statusCode)
+		w.Write(body)
+		return
+	}
+	response.Xmlns = tableau_namespace
+	body, err := xml.MarshalIndent(response, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml;charset=utf-8")
+	w.WriteHeader(statusCode)
+	io.WriteString(w, xml.Header)
+	w.Write(body)
+}
+
+func decode(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	return unmarshal(r.Header.Get("Content-Type"), body, v)
+}
+
+// unmarshal reads a request body, json when contentType says so and xml otherwise.
+func unmarshal(contentType string, body []byte, v interface{}) error {
+	if strings.HasPrefix(contentType, json_content_type) {
+		return json.Unmarshal(body, v)
+	}
+	return xml.Unmarshal(body, v)
+}
+
+func fail(statusCode int, code string, summary string, detail string) (int, *tsResponse) {
+	return statusCode, &tsResponse{Error: &tableau4go.Terror{Code: code, Summary: summary, Detail: detail}}
+}
+
+func badRequest(detail string) (int, *tsResponse) {
+	return fail(http.StatusBadRequest, ERROR_BAD_REQUEST, "Bad Request", detail)
+}
+
+func unauthorized() (int, *tsResponse) {
+	return fail(http.StatusUnauthorized, ERROR_UNAUTHORIZED, "Unauthorized Access", "Invalid authentication credentials were provided.")
+}
+
+func siteUnavailable(st *site) (int, *tsResponse) {
+	return fail(http.StatusForbidden, ERROR_SITE_UNAVAILABLE, "Site Unavailable", fmt.Sprintf("Site '%s' is %s.", st.ContentUrl, strings.ToLower(st.State)))
+}
+
+func notFound(r *http.Request) (int, *tsResponse) {
+	return fail(http.StatusNotFound, ERROR_RESOURCE_NOT_FOUND, "Resource Not Found", fmt.Sprintf("Unknown resource '%s' specified in URI.", r.URL.Path))
+}
+
+func siteNotFound(siteID string) (int, *tsResponse) {
+	return fail(http.StatusNotFound, ERROR_SITE_NOT_FOUND, "Site not found", fmt.Sprintf("Site '%s' could not be found.", siteID))
+}
+
+func projectNotFound(projectID string) (int, *tsResponse) {
+	return fail(http.StatusNotFound, ERROR_PROJECT_NOT_FOUND, "Resource Not Found", fmt.Sprintf("Project '%s' could not be found.", projectID))
+}
+
+func datasourceNotFound(datasourceID string) (int, *tsResponse) {
+	return fail(http.StatusNotFound, ERROR_DATASOURCE_NOT_FOUND, "Resource Not Found", fmt.Sprintf("Datasource '%s' could not be found.", datasourceID))
+}
+
+func userNotFound(userID string) (int, *tsResponse) {
+	return fail(http.StatusNotFound, ERROR_USER_NOT_FOUND, "Resource Not Found", fmt.Sprintf("User '%s' could not be found.", userID))
+}
+
+// paginate picks the page asked for by pageSize and pageNumber out of total items.
+func paginate(r *http.Request, total int) (int, int, *pagination) {
+	page := &pagination{PageNumber: 1, PageSize: default_page_size, TotalAvailable: total}
+	if n, err := strconv.Atoi(r.URL.Query().Get("pageSize")); err == nil && n > 0 {
+		page.PageSize = n
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("pageNumber")); err == nil && n > 0 {
+		page.PageNumber = n
+	}
+	start := (page.PageNumber - 1) * page.PageSize
+	if start > total {
+		start = total
+	}
+	end := start + page.PageSize
+	if end > total {
+		end = total
+	}
+	return start, end, page
+}
+
+// merge copies the fields set in src over dst, the way the update calls leave
+// out whatever they don't change.
+func merge(dst interface{}, src interface{}) {
+	to := reflect.ValueOf(dst).Elem()
+	from := reflect.ValueOf(src)
+	for i := 0; i < from.NumField(); i++ {
+		if !from.Field(i).IsZero() {
+			to.Field(i).Set(from.Field(i))
+		}
+	}
+}
+
+func (s *Server) signin(c *call) (int, *tsResponse) {
+	request := tableau4go.SigninRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	credentials := request.Request
+	contentUrl := ""
+	if credentials.Site != nil {
+		contentUrl = credentials.Site.ContentUrl
+	}
+	st := s.siteByContentUrl(contentUrl)
+	var u *user
+	if st != nil {
+		u = st.userByName(credentials.Name)
+	}
+	if u == nil || u.password != credentials.Password {
+		return fail(http.StatusUnauthorized, ERROR_SIGNIN, "Signin Error", "Error signing in to Tableau Server")
+	}
+	if st.State != "Active" {
+		return siteUnavailable(st)
+	}
+	userID := u.ID
+	if credentials.Impersonate != nil && len(credentials.Impersonate.ID) > 0 {
+		if st.user(credentials.Impersonate.ID) == nil {
+			return userNotFound(credentials.Impersonate.ID)
+		}
+		userID = credentials.Impersonate.ID
+	}
+	return s.startSession(st, userID)
+}
+
+func (s *Server) switchSite(c *call) (int, *tsResponse) {
+	request := tableau4go.SwitchSiteRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	st := s.siteByContentUrl(request.Request.ContentUrl)
+	if st == nil {
+		return siteNotFound(request.Request.ContentUrl)
+	}
+	var u *user
+	if current := s.site(c.session.siteID); current != nil {
+		if signedIn := current.user(c.session.userID); signedIn != nil {
+			u = st.userByName(signedIn.Name)
+		}
+	}
+	if u == nil {
+		return unauthorized()
+	}
+	if st.State != "Active" {
+		return siteUnavailable(st)
+	}
+	// switching hands out a new token, the old one stops working
+	delete(s.tokens, c.token)
+	return s.startSession(st, u.ID)
+}
+
+func (s *Server) startSession(st *site, userID string) (int, *tsResponse) {
+	token := newToken()
+	s.tokens[token] = authSession{siteID: st.ID, userID: userID}
+	credentials := &tableau4go.Credentials{
+		Token:       token,
+		Site:        &tableau4go.Site{ID: st.ID, ContentUrl: st.ContentUrl},
+		Impersonate: &tableau4go.User{ID: userID},
+	}
+	return http.StatusOK, &tsResponse{Credentials: credentials}
+}
+
+func (s *Server) signout(c *call) (int, *tsResponse) {
+	delete(s.tokens, c.token)
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) serverInfo(c *call) (int, *tsResponse) {
+	return http.StatusOK, &tsResponse{ServerInfo: &tableau4go.ServerInfo{ProductVersion: PRODUCT_VERSION, RestApiVersion: REST_API_VERSION}}
+}
+
+func (s *Server) querySites(c *call) (int, *tsResponse) {
+	if len(c.r.URL.Query().Get("key")) > 0 {
+		// the default site's content url is empty, leaving sites?key=contentUrl
+		return s.querySite(&call{r: c.r, ids: []string{""}})
+	}
+	start, end, page := paginate(c.r, len(s.sites))
+	sites := &tableau4go.Sites{}
+	for _, st := range s.sites[start:end] {
+		sites.Sites = append(sites.Sites, st.Site)
+	}
+	return http.StatusOK, &tsResponse{Pagination: page, Sites: sites}
+}
+
+func (s *Server) querySite(c *call) (int, *tsResponse) {
+	st := s.siteByKey(c.r, c.ids[0])
+	if st == nil {
+		return siteNotFound(c.ids[0])
+	}
+	retval := st.Site
+	if c.r.URL.Query().Get("includeStorage") == "true" {
+		retval.Usage = &tableau4go.SiteUsage{NumberOfUsers: len(st.users), Storage: st.storage()}
+	}
+	return http.StatusOK, &tsResponse{Site: &retval}
+}
+
+func (s *Server) createSite(c *call) (int, *tsResponse) {
+	request := tableau4go.SiteRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	if len(request.Request.Name) == 0 || len(request.Request.ContentUrl) == 0 {
+		return badRequest("A site requires a name and a contentUrl.")
+	}
+	if code, response := s.siteConflict(request.Request, ""); response != nil {
+		return code, response
+	}
+	st := s.addSite(request.Request)
+	return http.StatusCreated, &tsResponse{Site: &st.Site}
+}
+
+func (s *Server) updateSite(c *call) (int, *tsResponse) {
+	st := s.site(c.ids[0])
+	if st == nil {
+		return siteNotFound(c.ids[0])
+	}
+	request := tableau4go.SiteRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	if code, response := s.siteConflict(request.Request, st.ID); response != nil {
+		return code, response
+	}
+	id := st.ID
+	merge(&st.Site, request.Request)
+	st.ID = id
+	st.Usage = nil
+	return http.StatusOK, &tsResponse{Site: &st.Site}
+}
+
+func (s *Server) siteConflict(requested tableau4go.Site, siteID string) (int, *tsResponse) {
+	for _, st := range s.sites {
+		if st.ID == siteID {
+			continue
+		}
+		if len(requested.Name) > 0 && strings.EqualFold(st.Name, requested.Name) {
+			return fail(http.StatusConflict, ERROR_SITE_CONFLICT, "Resource Conflict", fmt.Sprintf("A site with the name '%s' already exists.", requested.Name))
+		}
+		if len(requested.ContentUrl) > 0 && strings.EqualFold(st.ContentUrl, requested.ContentUrl) {
+			return fail(http.StatusConflict, ERROR_SITE_CONFLICT, "Resource Conflict", fmt.Sprintf("A site with the contentUrl '%s' already exists.", requested.ContentUrl))
+		}
+	}
+	return 0, nil
+}
+
+func (s *Server) deleteSite(c *call) (int, *tsResponse) {
+	st := s.siteByKey(c.r, c.ids[0])
+	if st == nil {
+		return siteNotFound(c.ids[0])
+	}
+	for i := range s.sites {
+		if s.sites[i] == st {
+			s.sites = append(s.sites[:i], s.sites[i+1:]...)
+			break
+		}
+	}
+	for token, session := range s.tokens {
+		if session.siteID == st.ID {
+			delete(s.tokens, token)
+		}
+	}
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) queryProjects(c *call) (int, *tsResponse) {
+	start, end, page := paginate(c.r, len(c.site.projects))
+	projects := &tableau4go.Projects{Projects: append([]tableau4go.Project(nil), c.site.projects[start:end]...)}
+	return http.StatusOK, &tsResponse{Pagination: page, Projects: projects}
+}
+
+func (s *Server) createProject(c *call) (int, *tsResponse) {
+	request := tableau4go.CreateProjectRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	if len(request.Request.Name) == 0 {
+		return badRequest("A project requires a name.")
+	}
+	if code, response := c.site.projectConflict(request.Request.Name, ""); response != nil {
+		return code, response
+	}
+	project := c.site.addProject(request.Request)
+	return http.StatusCreated, &tsResponse{Project: &project}
+}
+
+func (s *Server) updateProject(c *call) (int, *tsResponse) {
+	project := c.site.project(c.ids[1])
+	if project == nil {
+		return projectNotFound(c.ids[1])
+	}
+	request := tableau4go.CreateProjectRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	if code, response := c.site.projectConflict(request.Request.Name, project.ID); response != nil {
+		return code, response
+	}
+	id := project.ID
+	merge(project, request.Request)
+	project.ID = id
+	retval := *project
+	return http.StatusOK, &tsResponse{Project: &retval}
+}
+
+func (s *Server) deleteProject(c *call) (int, *tsResponse) {
+	if c.site.project(c.ids[1]) == nil {
+		return projectNotFound(c.ids[1])
+	}
+	for i := range c.site.projects {
+		if c.site.projects[i].ID == c.ids[1] {
+			c.site.projects = append(c.site.projects[:i], c.site.projects[i+1:]...)
+			break
+		}
+	}
+	// the content of a project goes with it
+	kept := c.site.datasources[:0]
+	for _, ds := range c.site.datasources {
+		if ds.projectID != c.ids[1] {
+			kept = append(kept, ds)
+		}
+	}
+	c.site.datasources = kept
+	return http.StatusNoContent, nil
+}
+
+func (s *Server) queryDatasources(c *call) (int, *tsResponse) {
+	start, end, page := paginate(c.r, len(c.site.datasources))
+	datasources := &tableau4go.Datasources{}
+	for _, ds := range c.site.datasources[start:end] {
+		datasources.Datasources = append(datasources.Datasources, c.site.datasource(ds))
+	}
+	return http.StatusOK, &tsResponse{Pagination: page, Datasources: datasources}
+}
+
+func (s *Server) queryDatasource(c *call) (int, *tsResponse) {
+	ds := c.site.datasourceByID(c.ids[1])
+	if ds == nil {
+		return datasourceNotFound(c.ids[1])
+	}
+	retval := c.site.datasource(ds)
+	return http.StatusOK, &tsResponse{Datasource: &retval}
+}
+
+// publishDatasource takes the multipart/mixed body of Publish Data Source: the
+// request_payload part describing the datasource and the tableau_datasource file.
+func (s *Server) publishDatasource(c *call) (int, *tsResponse) {
+	datasourceType := c.r.URL.Query().Get("datasourceType")
+	switch datasourceType {
+	case "tds", "tdsx", "tde", "hyper":
+	default:
+		return badRequest(fmt.Sprintf("'%s' is not a valid datasource type.", datasourceType))
+	}
+	overwrite := c.r.URL.Query().Get("overwrite") == "true"
+	request, content, err := readPublish(c.r)
+	if err != nil {
+		return badRequest(err.Error())
+	}
+	metadata := request.Request
+	if len(metadata.Name) == 0 {
+		return badRequest("A datasource requires a name.")
+	}
+	projectID := c.site.projects[0].ID
+	if metadata.Project != nil && len(metadata.Project.ID) > 0 {
+		if c.site.project(metadata.Project.ID) == nil {
+			return projectNotFound(metadata.Project.ID)
+		}
+		projectID = metadata.Project.ID
+	}
+	for _, ds := range c.site.datasources {
+		if ds.projectID != projectID || !strings.EqualFold(ds.Name, metadata.Name) {
+			continue
+		}
+		if !overwrite {
+			return fail(http.StatusConflict, ERROR_DATASOURCE_CONFLICT, "Resource Conflict", fmt.Sprintf("A datasource named '%s' already exists in the project.", metadata.Name))
+		}
+		ds.content = content
+		ds.Size = int64(len(content))
+		ds.UpdatedAt = now()
+		ds.ownerID = c.session.userID
+		retval := c.site.datasource(ds)
+		return http.StatusCreated, &tsResponse{Datasource: &retval}
+	}
+	ds := &datasource{Datasource: metadata, projectID: projectID, ownerID: c.session.userID, content: content}
+	ds.ID = newID()
+	ds.ContentUrl = contentUrl(ds.Name)
+	ds.Size = int64(len(content))
+	ds.CreatedAt = now()
+	ds.UpdatedAt = ds.CreatedAt
+	// the credentials are for the server to connect with, it never hands them back
+	ds.ConnectionCredentials = nil
+	ds.Project = nil
+	ds.Owner = nil
+	c.site.datasources = append(c.site.datasources, ds)
+	retval := c.site.datasource(ds)
+	return http.StatusCreated, &tsResponse{Datasource: &retval}
+}
+
+func readPublish(r *http.Request) (tableau4go.DatasourceCreateRequest, []byte, error) {
+	request := tableau4go.DatasourceCreateRequest{}
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return request, nil, err
+	}
+	if mediaType != "multipart/mixed" || len(params["boundary"]) == 0 {
+		return request, nil, fmt.Errorf("Publishing requires a multipart/mixed body, got '%s'.", mediaType)
+	}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	var content []byte
+	havePayload := false
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return request, nil, err
+		}
+		body, err := ioutil.ReadAll(part)
+		if err != nil {
+			return request, nil, err
+		}
+		switch partName(part) {
+		case "request_payload":
+			if err := unmarshal(part.Header.Get("Content-Type"), body, &request); err != nil {
+				return request, nil, err
+			}
+			havePayload = true
+		case "tableau_datasource":
+			content = body
+		}
+	}
+	if !havePayload {
+		return request, nil, fmt.Errorf("The request_payload part is missing.")
+	}
+	if content == nil {
+		return request, nil, fmt.Errorf("The tableau_datasource part is missing.")
+	}
+	return request, content, nil
+}
+
+// partName reads the name from a part's Content-Disposition, which the publish
+// calls send bare (name="request_payload") rather than as form-data.
+func partName(part *multipart.Part) string {
+	disposition := strings.TrimPrefix(strings.TrimSpace(part.Header.Get("Content-Disposition")), "form-data;")
+	_, params, err := mime.ParseMediaType("form-data; " + disposition)
+	if err != nil {
+		return ""
+	}
+	return params["name"]
+}
+
+func (s *Server) updateDatasource(c *call) (int, *tsResponse) {
+	ds := c.site.datasourceByID(c.ids[1])
+	if ds == nil {
+		return datasourceNotFound(c.ids[1])
+	}
+	request := datasourceUpdate{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	update := request.Datasource
+	if update.Project != nil && len(update.Project.ID) > 0 {
+		if c.site.project(update.Project.ID) == nil {
+			return projectNotFound(update.Project.ID)
+		}
+		ds.projectID = update.Project.ID
+	}
+	if update.Owner != nil && len(update.Owner.ID) > 0 {
+		if c.site.user(update.Owner.ID) == nil {
+			return userNotFound(update.Owner.ID)
+		}
+		ds.ownerID = update.Owner.ID
+	}
+	if len(update.Name) > 0 {
+		ds.Name = update.Name
+	}
+	if update.IsCertified != nil {
+		ds.IsCertified = *update.IsCertified
+		if !ds.IsCertified {
+			ds.CertificationNote = ""
+		}
+	}
+	if update.CertificationNote != nil {
+		ds.CertificationNote = *update.CertificationNote
+	}
+	ds.UpdatedAt = now()
+	retval := c.site.datasource(ds)
+	return http.StatusOK, &tsResponse{Datasource: &retval}
+}
+
+func (s *Server) deleteDatasource(c *call) (int, *tsResponse) {
+	for i, ds := range c.site.datasources {
+		if ds.ID == c.ids[1] {
+			c.site.datasources = append(c.site.datasources[:i], c.site.datasources[i+1:]...)
+			return http.StatusNoContent, nil
+		}
+	}
+	return datasourceNotFound(c.ids[1])
+}
+
+func (s *Server) queryUsers(c *call) (int, *tsResponse) {
+	start, end, page := paginate(c.r, len(c.site.users))
+	siteUsers := &users{}
+	for _, u := range c.site.users[start:end] {
+		siteUsers.Users = append(siteUsers.Users, u.User)
+	}
+	return http.StatusOK, &tsResponse{Pagination: page, Users: siteUsers}
+}
+
+func (s *Server) queryUser(c *call) (int, *tsResponse) {
+	u := c.site.user(c.ids[1])
+	if u == nil {
+		return userNotFound(c.ids[1])
+	}
+	retval := u.User
+	return http.StatusOK, &tsResponse{User: &retval}
+}
+
+// addUser adds a user to the site. They have no password, so they can't sign in
+// until a test seeds one with AddUser.
+func (s *Server) addUser(c *call) (int, *tsResponse) {
+	request := tableau4go.UserRequest{}
+	if err := decode(c.r, &request); err != nil {
+		return badRequest(err.Error())
+	}
+	if len(request.Request.Name) == 0 || len(request.Request.SiteRole) == 0 {
+		return badRequest("A user requires a name and a siteRole.")
+	}
+	if c.site.userByName(request.Request.Name) != nil {
+		return fail(http.StatusConflict, ERROR_USER_CONFLICT, "Resource Conflict", fmt.Sprintf("The user '%s' is already a member of the site.", request.Request.Name))
+	}
+	u := c.site.addUser(request.Request, "")
+	retval := u.User
+	return http.StatusCreated, &tsResponse{User: &retval}
+}
+
+func (s *Server) removeUser(c *call) (int, *tsResponse) {
+	for i, u := range c.site.users {
+		if u.ID == c.ids[1] {
+			c.site.users = append(c.site.users[:i], c.site.users[i+1:]...)
+			return http.StatusNoContent, nil
+		}
+	}
+	return userNotFound(c.ids[1])
+}
+
+func (s *Server) siteByContentUrl(contentUrl string) *site {
+	for _, st := range s.sites {
+		if strings.EqualFold(st.ContentUrl, contentUrl) {
+			return st
+		}
+	}
+	return nil
+}
+
+// siteByKey finds the site a sites/{} url names: by id, or by name or contentUrl
+// when the key parameter says so.
+func (s *Server) siteByKey(r *http.Request, value string) *site {
+	switch r.URL.Query().Get("key") {
+	case "name":
+		for _, st := range s.sites {
+			if strings.EqualFold(st.Name, value) {
+				return st
+			}
+		}
+		return nil
+	case "contentUrl":
+		return s.siteByContentUrl(value)
+	}
+	return s.site(value)
+}
+
+func (st *site) storage() int {
+	total := 0
+	for _, ds := range st.datasources {
+		total += len(ds.content)
+	}
+	return total
+}
+
+func (st *site) project(projectID string) *tableau4go.Project {
+	for i := range st.projects {
+		if st.projects[i].ID == projectID {
+			return &st.projects[i]
+		}
+	}
+	return nil
+}
+
+func (st *site) projectConflict(name string, projectID string) (int, *tsResponse) {
+	for _, project := range st.projects {
+		if project.ID != projectID && len(name) > 0 && strings.EqualFold(project.Name, name) {
+			return fail(http.StatusConflict, ERROR_PROJECT_CONFLICT, "Resource Conflict", fmt.Sprintf("A project with the name '%s' already exists.", name))
+		}
+	}
+	return 0, nil
+}
+
+func (st *site) datasourceByID(datasourceID string) *datasource {
+	for _, ds := range st.datasources {
+		if ds.ID == datasourceID {
+			return ds
+		}
+	}
+	return nil
+}
+
+func (st *site) user(userID string) *user {
+	for _, u := range st.users {
+		if u.ID == userID {
+			return u
+		}
+	}
+	return nil
+}
+
+func (st *site) userByName(name string) *user {
+	for _, u := range st.users {
+		if strings.EqualFold(u.Name, name) {
+			return u
+		}
+	}
+	return nil
+}
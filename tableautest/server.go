@@ -0,0 +1,316 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tableautest provides an in-memory Tableau Server for tests. It speaks
+// enough of the REST API for tableau4go and the code built on it: sign in/out and
+// switch site, sites, projects, datasources (including publishing) and users,
+// answering with tsResponse and error documents shaped like the real ones, in
+// xml or, when the client asks for it, json.
+//
+//	server := tableautest.NewServer()
+//	defer server.Close()
+//	server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin", SiteRole: "ServerAdministrator"}, "secret")
+//	api := server.API()
+//	err := api.Signin("admin", "secret", "", "")
+//
+// Authentication is checked, permissions are not.
+//
+// For traffic with a real server, a Cassette records it once and replays it
+// from then on, see NewCassette.
+package tableautest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+const PRODUCT_VERSION = "2023.3.0"
+const REST_API_VERSION = "3.21"
+const DEFAULT_SITE_NAME = "Default"
+const DEFAULT_PROJECT_NAME = "Default"
+
+// The error codes the fake answers with, taken from the REST API reference.
+const (
+	ERROR_BAD_REQUEST          = "400000"
+	ERROR_SIGNIN               = "401001"
+	ERROR_UNAUTHORIZED         = "401002"
+	ERROR_SITE_NOT_FOUND       = "404000"
+	ERROR_RESOURCE_NOT_FOUND   = "404001"
+	ERROR_USER_NOT_FOUND       = "404002"
+	ERROR_PROJECT_NOT_FOUND    = "404005"
+	ERROR_DATASOURCE_NOT_FOUND = "404011"
+	ERROR_METHOD_NOT_ALLOWED   = "405000"
+	ERROR_SITE_CONFLICT        = "409001"
+	ERROR_DATASOURCE_CONFLICT  = "409004"
+	ERROR_PROJECT_CONFLICT     = "409006"
+	ERROR_USER_CONFLICT        = "409017"
+)
+
+// ERROR_SITE_UNAVAILABLE is the fake's own answer to signing in or switching to
+// a site that is not Active, the reference doesn't list one.
+const ERROR_SITE_UNAVAILABLE = "403000"
+
+// Server is a Tableau Server backed by memory. It starts with the Default site
+// holding the Default project and no users.
+type Server struct {
+	*httptest.Server
+	mu       sync.Mutex
+	sites    []*site
+	tokens   map[string]authSession
+	failures []failure
+}
+
+type site struct {
+	tableau4go.Site
+	projects    []tableau4go.Project
+	datasources []*datasource
+	users       []*user
+}
+
+type datasource struct {
+	tableau4go.Datasource
+	projectID string
+	ownerID   string
+	content   []byte
+}
+
+type user struct {
+	tableau4go.User
+	password string
+}
+
+type authSession struct {
+	siteID string
+	userID string
+}
+
+type failure struct {
+	statusCode int
+	err        tableau4go.Terror
+}
+
+// NewServer starts a Server, Close it when done.
+func NewServer() *Server {
+	s := &Server{tokens: make(map[string]authSession)}
+	s.addSite(tableau4go.Site{Name: DEFAULT_SITE_NAME})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// API returns a client for the server. Like DefaultApi, the site named Default
+// is signed in to as the empty content url.
+func (s *Server) API() *tableau4go.API {
+	return tableau4go.NewAPI(s.URL, REST_API_VERSION, tableau4go.BOUNDARY_STRING, DEFAULT_SITE_NAME, true)
+}
+
+// FailNext makes the next request, whatever it is, fail with statusCode and a
+// tsResponse error. Calls queue up, one failure per request.
+func (s *Server) FailNext(statusCode int, code string, summary string, detail string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{statusCode: statusCode, err: tableau4go.Terror{Code: code, Summary: summary, Detail: detail}})
+}
+
+func (s *Server) DefaultSite() tableau4go.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sites[0].Site
+}
+
+// AddSite seeds a site, with its Default project, and returns it with its id.
+func (s *Server) AddSite(newSite tableau4go.Site) tableau4go.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addSite(newSite).Site
+}
+
+// AddUser seeds a user who can sign in to the site with password.
+func (s *Server) AddUser(siteID string, newUser tableau4go.User, password string) tableau4go.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mustSite(siteID).addUser(newUser, password).User
+}
+
+func (s *Server) AddProject(siteID string, project tableau4go.Project) tableau4go.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mustSite(siteID).addProject(project)
+}
+
+// AddDatasource seeds a datasource as if it had been published with content. It
+// goes to the Default project unless datasource.Project names another.
+func (s *Server) AddDatasource(siteID string, newDatasource tableau4go.Datasource, content []byte) tableau4go.Datasource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.mustSite(siteID)
+	projectID := st.projects[0].ID
+	if newDatasource.Project != nil && len(newDatasource.Project.ID) > 0 {
+		projectID = newDatasource.Project.ID
+	}
+	ds := &datasource{Datasource: newDatasource, projectID: projectID, content: content}
+	ds.ID = newID()
+	ds.ContentUrl = contentUrl(ds.Name)
+	ds.Size = int64(len(content))
+	ds.CreatedAt = now()
+	ds.UpdatedAt = ds.CreatedAt
+	ds.ConnectionCredentials = nil
+	st.datasources = append(st.datasources, ds)
+	return st.datasource(ds)
+}
+
+func (s *Server) Sites() []tableau4go.Site {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	retval := make([]tableau4go.Site, 0, len(s.sites))
+	for _, st := range s.sites {
+		retval = append(retval, st.Site)
+	}
+	return retval
+}
+
+func (s *Server) Projects(siteID string) []tableau4go.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]tableau4go.Project(nil), s.mustSite(siteID).projects...)
+}
+
+func (s *Server) Datasources(siteID string) []tableau4go.Datasource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.mustSite(siteID)
+	retval := make([]tableau4go.Datasource, 0, len(st.datasources))
+	for _, ds := range st.datasources {
+		retval = append(retval, st.datasource(ds))
+	}
+	return retval
+}
+
+// DatasourceContent returns the file last published for the datasource, nil if
+// there is no such datasource.
+func (s *Server) DatasourceContent(siteID string, datasourceID string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ds := range s.mustSite(siteID).datasources {
+		if ds.ID == datasourceID {
+			return ds.content
+		}
+	}
+	return nil
+}
+
+func (s *Server) Users(siteID string) []tableau4go.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.mustSite(siteID)
+	retval := make([]tableau4go.User, 0, len(st.users))
+	for _, u := range st.users {
+		retval = append(retval, u.User)
+	}
+	return retval
+}
+
+func (s *Server) addSite(newSite tableau4go.Site) *site {
+	st := &site{Site: newSite}
+	st.ID = newID()
+	if len(st.State) == 0 {
+		st.State = "Active"
+	}
+	if len(st.AdminMode) == 0 {
+		st.AdminMode = tableau4go.ADMIN_MODE_CONTENT_AND_USERS
+	}
+	st.Usage = nil
+	st.addProject(tableau4go.Project{Name: DEFAULT_PROJECT_NAME, Description: "The default project that was automatically created by Tableau."})
+	s.sites = append(s.sites, st)
+	return st
+}
+
+// mustSite is for the seeding helpers, where an unknown site is a mistake in the test.
+func (s *Server) mustSite(siteID string) *site {
+	st := s.site(siteID)
+	if st == nil {
+		panic(fmt.Sprintf("tableautest: no site with id %q", siteID))
+	}
+	return st
+}
+
+func (s *Server) site(siteID string) *site {
+	for _, st := range s.sites {
+		if st.ID == siteID {
+			return st
+		}
+	}
+	return nil
+}
+
+func (st *site) addUser(newUser tableau4go.User, password string) *user {
+	u := &user{User: newUser, password: password}
+	u.ID = newID()
+	st.users = append(st.users, u)
+	return u
+}
+
+func (st *site) addProject(project tableau4go.Project) tableau4go.Project {
+	project.ID = newID()
+	st.projects = append(st.projects, project)
+	return project
+}
+
+// datasource renders ds as the server reports it, with its project and owner.
+func (st *site) datasource(ds *datasource) tableau4go.Datasource {
+	retval := ds.Datasource
+	retval.Project = &tableau4go.Project{ID: ds.projectID}
+	for _, project := range st.projects {
+		if project.ID == ds.projectID {
+			retval.Project.Name = project.Name
+		}
+	}
+	if len(ds.ownerID) > 0 {
+		retval.Owner = &tableau4go.User{ID: ds.ownerID}
+	}
+	return retval
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("%x", b)
+}
+
+// contentUrl derives the url name the server gives content: the name without
+// anything but letters and digits.
+func contentUrl(name string) string {
+	retval := make([]rune, 0, len(name))
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			retval = append(retval, r)
+		}
+	}
+	return string(retval)
+}
+
+func now() *time.Time {
+	t := time.Now().UTC().Truncate(time.Second)
+	return &t
+}
@@ -0,0 +1,288 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableautest
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+const tds = `<?xml version='1.0' encoding='utf-8' ?><datasource version='10.0'></datasource>`
+
+func newSignedIn(t *testing.T) (*Server, *tableau4go.API) {
+	t.Helper()
+	server := NewServer()
+	t.Cleanup(server.Close)
+	server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin", SiteRole: "ServerAdministrator"}, "secret")
+	api := server.API()
+	if err := api.Signin("admin", "secret", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	return server, api
+}
+
+func tokenCount(s *Server) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.tokens)
+}
+
+// errorCode is the Tableau code of err, failing the test when there is none.
+func errorCode(t *testing.T, err error, statusCode int) string {
+	t.Helper()
+	var apiErr *tableau4go.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != statusCode {
+		t.Errorf("status = %d, want %d", apiErr.StatusCode, statusCode)
+	}
+	return apiErr.Code
+}
+
+func TestSigninFailure(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	other := server.AddSite(tableau4go.Site{Name: "Other", ContentUrl: "other"})
+	server.AddUser(server.DefaultSite().ID, tableau4go.User{Name: "admin"}, "secret")
+	cases := []struct {
+		name       string
+		username   string
+		password   string
+		contentUrl string
+	}{
+		{"wrong password", "admin", "wrong", ""},
+		{"unknown user", "nobody", "secret", ""},
+		{"not a user of the site", "admin", "secret", other.ContentUrl},
+		{"unknown site", "admin", "secret", "nowhere"},
+	}
+	for _, c := range cases {
+		api := server.API()
+		err := api.Signin(c.username, c.password, c.contentUrl, "")
+		if code := errorCode(t, err, http.StatusUnauthorized); code != ERROR_SIGNIN {
+			t.Errorf("%s: code = %s, want %s", c.name, code, ERROR_SIGNIN)
+		}
+		if len(api.AuthToken()) > 0 {
+			t.Errorf("%s: failed sign in left a token", c.name)
+		}
+	}
+	if tokenCount(server) > 0 {
+		t.Errorf("failed sign ins issued %d tokens", tokenCount(server))
+	}
+}
+
+func TestTokenScopedToSite(t *testing.T) {
+	server, api := newSignedIn(t)
+	other := server.AddSite(tableau4go.Site{Name: "Other", ContentUrl: "other"})
+	server.AddUser(other.ID, tableau4go.User{Name: "admin"}, "secret")
+	if _, err := api.QueryProjects(api.SiteID()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := api.QueryProjects(other.ID)
+	if code := errorCode(t, err, http.StatusUnauthorized); code != ERROR_UNAUTHORIZED {
+		t.Errorf("other site: code = %s, want %s", code, ERROR_UNAUTHORIZED)
+	}
+	oldToken := api.AuthToken()
+	if err := api.SwitchSite(other.ContentUrl); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.QueryProjects(other.ID); err != nil {
+		t.Errorf("after switching: %v", err)
+	}
+	server.mu.Lock()
+	_, ok := server.tokens[oldToken]
+	server.mu.Unlock()
+	if ok {
+		t.Error("switching site left the old token working")
+	}
+	if err := api.Signout(); err != nil {
+		t.Fatal(err)
+	}
+	if tokenCount(server) > 0 {
+		t.Errorf("%d tokens left after sign out", tokenCount(server))
+	}
+}
+
+func TestSuspendedSite(t *testing.T) {
+	server, api := newSignedIn(t)
+	suspended := server.AddSite(tableau4go.Site{Name: "Closed", ContentUrl: "closed", State: "Suspended"})
+	server.AddUser(suspended.ID, tableau4go.User{Name: "admin"}, "secret")
+	err := api.SwitchSite(suspended.ContentUrl)
+	if code := errorCode(t, err, http.StatusForbidden); code != ERROR_SITE_UNAVAILABLE {
+		t.Errorf("switch: code = %s, want %s", code, ERROR_SITE_UNAVAILABLE)
+	}
+	// a refused switch leaves the token where it was
+	if _, err := api.QueryProjects(api.SiteID()); err != nil {
+		t.Errorf("after the refused switch: %v", err)
+	}
+	err = server.API().Signin("admin", "secret", suspended.ContentUrl, "")
+	if code := errorCode(t, err, http.StatusForbidden); code != ERROR_SITE_UNAVAILABLE {
+		t.Errorf("sign in: code = %s, want %s", code, ERROR_SITE_UNAVAILABLE)
+	}
+}
+
+func TestPublishOverwrite(t *testing.T) {
+	server, api := newSignedIn(t)
+	siteID := api.SiteID()
+	published, err := api.PublishTDS(siteID, tableau4go.Datasource{Name: "Sales"}, tds, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published == nil || len(published.ID) == 0 || published.Project == nil || published.Project.Name != DEFAULT_PROJECT_NAME {
+		t.Fatalf("published = %+v", published)
+	}
+	_, err = api.PublishTDS(siteID, tableau4go.Datasource{Name: "Sales"}, tds, false)
+	if code := errorCode(t, err, http.StatusConflict); code != ERROR_DATASOURCE_CONFLICT {
+		t.Errorf("republish: code = %s, want %s", code, ERROR_DATASOURCE_CONFLICT)
+	}
+	changed := tds + "<!-- v2 -->"
+	overwritten, err := api.PublishTDS(siteID, tableau4go.Datasource{Name: "Sales"}, changed, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overwritten.ID != published.ID {
+		t.Errorf("overwrite made a new datasource %s, want %s", overwritten.ID, published.ID)
+	}
+	if got := len(server.Datasources(siteID)); got != 1 {
+		t.Errorf("%d datasources, want 1", got)
+	}
+	if content := string(server.DatasourceContent(siteID, published.ID)); content != changed {
+		t.Errorf("content = %q, want the overwritten file", content)
+	}
+}
+
+func TestPublishValidation(t *testing.T) {
+	_, api := newSignedIn(t)
+	_, err := api.PublishTDS(api.SiteID(), tableau4go.Datasource{}, tds, false)
+	if code := errorCode(t, err, http.StatusBadRequest); code != ERROR_BAD_REQUEST {
+		t.Errorf("no name: code = %s", code)
+	}
+	_, err = api.PublishTDS(api.SiteID(), tableau4go.Datasource{Name: "Sales", Project: &tableau4go.Project{ID: "missing"}}, tds, false)
+	if code := errorCode(t, err, http.StatusNotFound); code != ERROR_PROJECT_NOT_FOUND {
+		t.Errorf("unknown project: code = %s", code)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	server, api := newSignedIn(t)
+	server.FailNext(http.StatusServiceUnavailable, "503000", "Service Unavailable", "first")
+	server.FailNext(http.StatusInternalServerError, "500000", "Internal Server Error", "second")
+	for _, want := range []struct {
+		status int
+		detail string
+	}{{http.StatusServiceUnavailable, "first"}, {http.StatusInternalServerError, "second"}} {
+		_, err := api.QueryProjects(api.SiteID())
+		var apiErr *tableau4go.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != want.status || apiErr.Detail != want.detail {
+			t.Errorf("err = %v, want %d %s", err, want.status, want.detail)
+		}
+	}
+	if _, err := api.QueryProjects(api.SiteID()); err != nil {
+		t.Errorf("after the queued failures: %v", err)
+	}
+}
+
+func TestDeleteProjectCascades(t *testing.T) {
+	server, api := newSignedIn(t)
+	siteID := api.SiteID()
+	project, err := api.CreateProject(siteID, tableau4go.Project{Name: "Finance"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.AddDatasource(siteID, tableau4go.Datasource{Name: "Ledger", Project: &tableau4go.Project{ID: project.ID}}, []byte(tds))
+	kept := server.AddDatasource(siteID, tableau4go.Datasource{Name: "Kept"}, []byte(tds))
+	if err := api.DeleteProject(siteID, project.ID); err != nil {
+		t.Fatal(err)
+	}
+	datasources, err := api.QueryDatasources(siteID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasources) != 1 || datasources[0].ID != kept.ID {
+		t.Errorf("datasources after deleting the project = %+v", datasources)
+	}
+	err = api.DeleteProject(siteID, project.ID)
+	if code := errorCode(t, err, http.StatusNotFound); code != ERROR_PROJECT_NOT_FOUND {
+		t.Errorf("second delete: code = %s", code)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	server, api := newSignedIn(t)
+	siteID := api.SiteID()
+	for _, name := range []string{"A", "B", "C", "D"} {
+		server.AddProject(siteID, tableau4go.Project{Name: name})
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/"+REST_API_VERSION+"/sites/"+siteID+"/projects?pageSize=2&pageNumber=3", nil)
+	req.Header.Set(auth_header, api.AuthToken())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	page := tableau4go.QueryProjectsResponse{}
+	if err := xml.Unmarshal(body, &page); err != nil {
+		t.Fatal(err)
+	}
+	// Default plus four, the third page of two holds the last one
+	if len(page.Projects.Projects) != 1 || page.Projects.Projects[0].Name != "D" {
+		t.Errorf("page 3 = %+v", page.Projects.Projects)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	server, api := newSignedIn(t)
+	req, _ := http.NewRequest(http.MethodPatch, server.URL+"/api/"+REST_API_VERSION+"/sites/"+api.SiteID()+"/projects", nil)
+	req.Header.Set(auth_header, api.AuthToken())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("PATCH: status = %d", resp.StatusCode)
+	}
+}
+
+// TestJSONWireFormat checks the json on the wire rather than what the models
+// make of it: numbers quoted and the version an object, as Tableau Server sends them.
+func TestJSONWireFormat(t *testing.T) {
+	server, api := newSignedIn(t)
+	server.AddDatasource(api.SiteID(), tableau4go.Datasource{Name: "Sales"}, []byte(tds))
+	for path, wants := range map[string][]string{
+		"/serverinfo": {`"productVersion":{"value":"` + PRODUCT_VERSION + `"}`},
+		"/sites/" + api.SiteID() + "/datasources": {`"size":"` + strconv.Itoa(len(tds)) + `"`, `"totalAvailable":"1"`},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/"+REST_API_VERSION+path, nil)
+		req.Header.Set(auth_header, api.AuthToken())
+		req.Header.Set("Accept", json_content_type)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		for _, want := range wants {
+			if !strings.Contains(string(body), want) {
+				t.Errorf("%s: %s lacks %s", path, body, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#add_tags_to_workbook
+func (api *API) AddTagsToWorkbook(siteId, workbookId string, labels ...string) ([]Tag, error) {
+	return api.addTags(siteId, "workbooks", workbookId, labels)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#delete_tag_from_workbook
+func (api *API) DeleteTagFromWorkbook(siteId, workbookId, label string) error {
+	return api.deleteTag(siteId, "workbooks", workbookId, label)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#add_tags_to_data_source
+func (api *API) AddTagsToDatasource(siteId, datasourceId string, labels ...string) ([]Tag, error) {
+	return api.addTags(siteId, "datasources", datasourceId, labels)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_data_sources.htm#delete_tag_from_data_source
+func (api *API) DeleteTagFromDatasource(siteId, datasourceId, label string) error {
+	return api.deleteTag(siteId, "datasources", datasourceId, label)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#add_tags_to_view
+func (api *API) AddTagsToView(siteId, viewId string, labels ...string) ([]Tag, error) {
+	return api.addTags(siteId, "views", viewId, labels)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#delete_tag_from_view
+func (api *API) DeleteTagFromView(siteId, viewId, label string) error {
+	return api.deleteTag(siteId, "views", viewId, label)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#add_tags_to_flow
+func (api *API) AddTagsToFlow(siteId, flowId string, labels ...string) ([]Tag, error) {
+	return api.addTags(siteId, "flows", flowId, labels)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_flow.htm#delete_tag_from_flow
+func (api *API) DeleteTagFromFlow(siteId, flowId, label string) error {
+	return api.deleteTag(siteId, "flows", flowId, label)
+}
+
+func (api *API) addTags(siteId, contentType, contentId string, labels []string) ([]Tag, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/%s/%s/tags", api.Server, api.Version, siteId, contentType, contentId)
+	addTagsRequest := AddTagsRequest{Request: NewTags(labels...)}
+	headers := make(map[string]string)
+	payload, err := api.encode(addTagsRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := AddTagsResponse{}
+	err = api.makeRequest(url, PUT, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Tags.Tags, err
+}
+
+func (api *API) deleteTag(siteId, contentType, contentId, label string) error {
+	requestUrl := fmt.Sprintf("%s/api/%s/sites/%s/%s/%s/tags/%s", api.Server, api.Version, siteId, contentType, contentId, url.PathEscape(label))
+	return api.delete(requestUrl)
+}
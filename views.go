@@ -0,0 +1,169 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+const IMAGE_RESOLUTION_HIGH = "high"
+
+const PAGE_TYPE_A3 = "A3"
+const PAGE_TYPE_A4 = "A4"
+const PAGE_TYPE_A5 = "A5"
+const PAGE_TYPE_B5 = "B5"
+const PAGE_TYPE_EXECUTIVE = "Executive"
+const PAGE_TYPE_FOLIO = "Folio"
+const PAGE_TYPE_LEDGER = "Ledger"
+const PAGE_TYPE_LEGAL = "Legal"
+const PAGE_TYPE_LETTER = "Letter"
+const PAGE_TYPE_NOTE = "Note"
+const PAGE_TYPE_QUARTO = "Quarto"
+const PAGE_TYPE_TABLOID = "Tabloid"
+
+const ORIENTATION_PORTRAIT = "Portrait"
+const ORIENTATION_LANDSCAPE = "Landscape"
+
+// ViewFilters are sent as vf_<field>=<value> query parameters, e.g.
+// ViewFilters{"Region": "West"} becomes vf_Region=West.
+type ViewFilters map[string]string
+
+func (vf ViewFilters) addTo(values url.Values) {
+	for field, value := range vf {
+		values.Set("vf_"+field, value)
+	}
+}
+
+type ImageOptions struct {
+	Resolution string
+	// MaxAge is the number of minutes a cached image may be served for, 0 uses the server default
+	MaxAge  int
+	Filters ViewFilters
+}
+
+func (o ImageOptions) values() url.Values {
+	values := url.Values{}
+	if len(o.Resolution) > 0 {
+		values.Set("resolution", o.Resolution)
+	}
+	addMaxAge(values, o.MaxAge)
+	o.Filters.addTo(values)
+	return values
+}
+
+type PDFOptions struct {
+	PageType    string
+	Orientation string
+	MaxAge      int
+	Filters     ViewFilters
+}
+
+func (o PDFOptions) values() url.Values {
+	values := url.Values{}
+	if len(o.PageType) > 0 {
+		values.Set("type", o.PageType)
+	}
+	if len(o.Orientation) > 0 {
+		values.Set("orientation", o.Orientation)
+	}
+	addMaxAge(values, o.MaxAge)
+	o.Filters.addTo(values)
+	return values
+}
+
+type DataOptions struct {
+	MaxAge  int
+	Filters ViewFilters
+}
+
+func (o DataOptions) values() url.Values {
+	values := url.Values{}
+	addMaxAge(values, o.MaxAge)
+	o.Filters.addTo(values)
+	return values
+}
+
+func addMaxAge(values url.Values, maxAge int) {
+	if maxAge > 0 {
+		values.Set("maxAge", strconv.Itoa(maxAge))
+	}
+}
+
+func withQuery(requestUrl string, values url.Values) string {
+	if len(values) == 0 {
+		return requestUrl
+	}
+	return requestUrl + "?" + values.Encode()
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_views_for_site
+func (api *API) QueryViewsForSite(siteId string, includeUsageStatistics bool) ([]View, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/views", api.Server, api.Version, siteId)
+	if includeUsageStatistics {
+		url += fmt.Sprintf("?includeUsageStatistics=%v", includeUsageStatistics)
+	}
+	return api.queryViews(url)
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_views_for_workbook
+func (api *API) QueryViewsForWorkbook(siteId, workbookId string, includeUsageStatistics bool) ([]View, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/views", api.Server, api.Version, siteId, workbookId)
+	if includeUsageStatistics {
+		url += fmt.Sprintf("?includeUsageStatistics=%v", includeUsageStatistics)
+	}
+	return api.queryViews(url)
+}
+
+func (api *API) queryViews(url string) ([]View, error) {
+	headers := make(map[string]string)
+	retval := QueryViewsResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Views.Views, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#get_view
+func (api *API) QueryView(siteId, viewId string) (View, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/views/%s", api.Server, api.Version, siteId, viewId)
+	headers := make(map[string]string)
+	retval := QueryViewResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.View, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_view_image
+func (api *API) QueryViewImage(siteId, viewId string, options ImageOptions, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/views/%s/image", api.Server, api.Version, siteId, viewId)
+	return api.download(withQuery(url, options.values()), w, make(map[string]string))
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_view_pdf
+func (api *API) QueryViewPDF(siteId, viewId string, options PDFOptions, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/views/%s/pdf", api.Server, api.Version, siteId, viewId)
+	return api.download(withQuery(url, options.values()), w, make(map[string]string))
+}
+
+// QueryViewData writes the view's summary data as CSV.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#query_view_data
+func (api *API) QueryViewData(siteId, viewId string, options DataOptions, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/views/%s/data", api.Server, api.Version, siteId, viewId)
+	return api.download(withQuery(url, options.values()), w, make(map[string]string))
+}
+
+// DownloadViewCrosstabExcel writes the view's crosstab as an .xlsx file.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#download_view_crosstab_excel
+func (api *API) DownloadViewCrosstabExcel(siteId, viewId string, options DataOptions, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/views/%s/crosstab/excel", api.Server, api.Version, siteId, viewId)
+	return api.download(withQuery(url, options.values()), w, make(map[string]string))
+}
@@ -0,0 +1,303 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// aggregations and date functions for VizQLField.Function
+const (
+	VIZQL_SUM            = "SUM"
+	VIZQL_AVG            = "AVG"
+	VIZQL_MEDIAN         = "MEDIAN"
+	VIZQL_COUNT          = "COUNT"
+	VIZQL_COUNTD         = "COUNTD"
+	VIZQL_MIN            = "MIN"
+	VIZQL_MAX            = "MAX"
+	VIZQL_STDEV          = "STDEV"
+	VIZQL_VAR            = "VAR"
+	VIZQL_YEAR           = "YEAR"
+	VIZQL_QUARTER        = "QUARTER"
+	VIZQL_MONTH          = "MONTH"
+	VIZQL_WEEK           = "WEEK"
+	VIZQL_DAY            = "DAY"
+	VIZQL_TRUNC_YEAR     = "TRUNC_YEAR"
+	VIZQL_TRUNC_QUARTER  = "TRUNC_QUARTER"
+	VIZQL_TRUNC_MONTH    = "TRUNC_MONTH"
+	VIZQL_TRUNC_WEEK     = "TRUNC_WEEK"
+	VIZQL_TRUNC_DAY      = "TRUNC_DAY"
+	VIZQL_SORT_ASC       = "ASC"
+	VIZQL_SORT_DESC      = "DESC"
+	VIZQL_FILTER_SET     = "SET"
+	VIZQL_FILTER_MATCH   = "MATCH"
+	VIZQL_FILTER_TOP     = "TOP"
+	VIZQL_FILTER_NUMERIC = "QUANTITATIVE_NUMERICAL"
+	VIZQL_FILTER_DATE    = "QUANTITATIVE_DATE"
+	VIZQL_RANGE          = "RANGE"
+	VIZQL_RANGE_MIN      = "MIN"
+	VIZQL_RANGE_MAX      = "MAX"
+)
+
+type VizQLDatasource struct {
+	DatasourceLuid string `json:"datasourceLuid"`
+}
+
+type VizQLField struct {
+	FieldCaption     string `json:"fieldCaption,omitempty"`
+	Function         string `json:"function,omitempty"`
+	Calculation      string `json:"calculation,omitempty"`
+	FieldAlias       string `json:"fieldAlias,omitempty"`
+	MaxDecimalPlaces *int   `json:"maxDecimalPlaces,omitempty"`
+	SortDirection    string `json:"sortDirection,omitempty"`
+	SortPriority     int    `json:"sortPriority,omitempty"`
+}
+
+type VizQLFilterField struct {
+	FieldCaption string `json:"fieldCaption,omitempty"`
+	Function     string `json:"function,omitempty"`
+	Calculation  string `json:"calculation,omitempty"`
+}
+
+// VizQLFilter covers every filter type, only the fields for FilterType are sent.
+type VizQLFilter struct {
+	Field                  VizQLFilterField  `json:"field"`
+	FilterType             string            `json:"filterType"`
+	Values                 []interface{}     `json:"values,omitempty"`
+	Exclude                bool              `json:"exclude,omitempty"`
+	Contains               string            `json:"contains,omitempty"`
+	StartsWith             string            `json:"startsWith,omitempty"`
+	EndsWith               string            `json:"endsWith,omitempty"`
+	QuantitativeFilterType string            `json:"quantitativeFilterType,omitempty"`
+	Min                    interface{}       `json:"min,omitempty"`
+	Max                    interface{}       `json:"max,omitempty"`
+	MinDate                string            `json:"minDate,omitempty"`
+	MaxDate                string            `json:"maxDate,omitempty"`
+	HowMany                int               `json:"howMany,omitempty"`
+	FieldToMeasure         *VizQLFilterField `json:"fieldToMeasure,omitempty"`
+	Direction              string            `json:"direction,omitempty"`
+}
+
+func SetFilter(fieldCaption string, values ...interface{}) VizQLFilter {
+	return VizQLFilter{Field: VizQLFilterField{FieldCaption: fieldCaption}, FilterType: VIZQL_FILTER_SET, Values: values}
+}
+
+func MatchFilter(fieldCaption string, contains string) VizQLFilter {
+	return VizQLFilter{Field: VizQLFilterField{FieldCaption: fieldCaption}, FilterType: VIZQL_FILTER_MATCH, Contains: contains}
+}
+
+// RangeFilter keeps rows where function(fieldCaption) is between min and max inclusive.
+func RangeFilter(fieldCaption string, function string, min, max interface{}) VizQLFilter {
+	return VizQLFilter{
+		Field:                  VizQLFilterField{FieldCaption: fieldCaption, Function: function},
+		FilterType:             VIZQL_FILTER_NUMERIC,
+		QuantitativeFilterType: VIZQL_RANGE,
+		Min:                    min,
+		Max:                    max,
+	}
+}
+
+// DateRangeFilter takes dates formatted as YYYY-MM-DD.
+func DateRangeFilter(fieldCaption string, minDate, maxDate string) VizQLFilter {
+	return VizQLFilter{
+		Field:                  VizQLFilterField{FieldCaption: fieldCaption},
+		FilterType:             VIZQL_FILTER_DATE,
+		QuantitativeFilterType: VIZQL_RANGE,
+		MinDate:                minDate,
+		MaxDate:                maxDate,
+	}
+}
+
+// TopFilter keeps the howMany values of fieldCaption ranked by function(measureCaption).
+func TopFilter(fieldCaption string, howMany int, measureCaption string, function string, direction string) VizQLFilter {
+	return VizQLFilter{
+		Field:          VizQLFilterField{FieldCaption: fieldCaption},
+		FilterType:     VIZQL_FILTER_TOP,
+		HowMany:        howMany,
+		FieldToMeasure: &VizQLFilterField{FieldCaption: measureCaption, Function: function},
+		Direction:      direction,
+	}
+}
+
+// VizQLQuery is built up with chained calls, e.g.
+//
+//	NewVizQLQuery().Dimension("Region").Measure("Sales", VIZQL_SUM).
+//		Where(SetFilter("Category", "Furniture")).OrderBy("Sales", VIZQL_SORT_DESC)
+type VizQLQuery struct {
+	Fields  []VizQLField  `json:"fields"`
+	Filters []VizQLFilter `json:"filters,omitempty"`
+}
+
+func NewVizQLQuery() *VizQLQuery {
+	return &VizQLQuery{}
+}
+
+func (q *VizQLQuery) Dimension(fieldCaption string) *VizQLQuery {
+	q.Fields = append(q.Fields, VizQLField{FieldCaption: fieldCaption})
+	return q
+}
+
+func (q *VizQLQuery) Measure(fieldCaption string, function string) *VizQLQuery {
+	q.Fields = append(q.Fields, VizQLField{FieldCaption: fieldCaption, Function: function})
+	return q
+}
+
+// Calculation adds an ad hoc calculated field, e.g. ("Margin", "SUM([Profit])/SUM([Sales])").
+func (q *VizQLQuery) Calculation(alias string, calculation string) *VizQLQuery {
+	q.Fields = append(q.Fields, VizQLField{FieldAlias: alias, Calculation: calculation})
+	return q
+}
+
+func (q *VizQLQuery) Where(filters ...VizQLFilter) *VizQLQuery {
+	q.Filters = append(q.Filters, filters...)
+	return q
+}
+
+// OrderBy sorts on a field already in the query, matched by caption or alias.
+// Each call adds the next sort priority.
+func (q *VizQLQuery) OrderBy(field string, direction string) *VizQLQuery {
+	priority := 1
+	for _, f := range q.Fields {
+		if f.SortPriority >= priority {
+			priority = f.SortPriority + 1
+		}
+	}
+	for i := range q.Fields {
+		if q.Fields[i].FieldCaption == field || q.Fields[i].FieldAlias == field {
+			q.Fields[i].SortDirection = direction
+			q.Fields[i].SortPriority = priority
+			break
+		}
+	}
+	return q
+}
+
+type VizQLFieldMetadata struct {
+	FieldName      string `json:"fieldName"`
+	FieldCaption   string `json:"fieldCaption"`
+	DataType       string `json:"dataType"`
+	LogicalTableID string `json:"logicalTableId"`
+}
+
+type VizQLError struct {
+	StatusCode int    `json:"-"`
+	ErrorCode  string `json:"errorCode"`
+	Message    string `json:"message"`
+	Datetime   string `json:"datetime"`
+}
+
+// Is matches the same sentinels as APIError, e.g. ErrUnauthorized.
+func (e VizQLError) Is(target error) bool {
+	sentinel := statusSentinel(e.StatusCode, "")
+	return sentinel != nil && sentinel == target
+}
+
+func (e VizQLError) Error() string {
+	return fmt.Sprintf("VizQL Data Service: Status:%d, Code:%s, Message:%s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+// VizQLRows iterates the rows of a query result in the style of sql.Rows:
+//
+//	for rows.Next() {
+//		var r struct{ Region string; Sales float64 `json:"SUM(Sales)"` }
+//		err := rows.Scan(&r)
+//	}
+type VizQLRows struct {
+	rows    []json.RawMessage
+	current int
+}
+
+func (r *VizQLRows) Len() int {
+	return len(r.rows)
+}
+
+func (r *VizQLRows) Next() bool {
+	if r.current < len(r.rows) {
+		r.current++
+		return true
+	}
+	return false
+}
+
+// Scan decodes the current row into dest, a struct with json tags or a map. As
+// with encoding/json, a column missing from the row leaves its field untouched
+// and one of the wrong type is a *json.UnmarshalTypeError.
+func (r *VizQLRows) Scan(dest interface{}) error {
+	if r.current == 0 || r.current > len(r.rows) {
+		return fmt.Errorf("Scan called without a successful Next")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(r.rows[r.current-1]))
+	decoder.UseNumber()
+	return decoder.Decode(dest)
+}
+
+// Map returns the current row keyed by column, numbers come back as json.Number.
+func (r *VizQLRows) Map() (map[string]interface{}, error) {
+	row := make(map[string]interface{})
+	err := r.Scan(&row)
+	return row, err
+}
+
+type vizqlQueryRequest struct {
+	Datasource VizQLDatasource        `json:"datasource"`
+	Query      *VizQLQuery            `json:"query,omitempty"`
+	Options    map[string]interface{} `json:"options,omitempty"`
+}
+
+// https://help.tableau.com/current/api/vizql-data-service/en-us/reference/index.html#tag/HeadlessBI/operation/ReadMetadata
+func (api *API) VizQLReadMetadata(datasourceId string) ([]VizQLFieldMetadata, error) {
+	url := fmt.Sprintf("%s/api/v1/vizql-data-service/read-metadata", api.Server)
+	retval := struct {
+		Data []VizQLFieldMetadata `json:"data"`
+	}{}
+	err := api.vizqlRequest(url, vizqlQueryRequest{Datasource: VizQLDatasource{DatasourceLuid: datasourceId}}, &retval)
+	return retval.Data, err
+}
+
+// https://help.tableau.com/current/api/vizql-data-service/en-us/reference/index.html#tag/HeadlessBI/operation/QueryDatasource
+func (api *API) VizQLQueryDatasource(datasourceId string, query *VizQLQuery) (*VizQLRows, error) {
+	url := fmt.Sprintf("%s/api/v1/vizql-data-service/query-datasource", api.Server)
+	request := vizqlQueryRequest{
+		Datasource: VizQLDatasource{DatasourceLuid: datasourceId},
+		Query:      query,
+		Options:    map[string]interface{}{"returnFormat": "OBJECTS"},
+	}
+	retval := struct {
+		Data []json.RawMessage `json:"data"`
+	}{}
+	err := api.vizqlRequest(url, request, &retval)
+	if err != nil {
+		return nil, err
+	}
+	return &VizQLRows{rows: retval.Data}, nil
+}
+
+func (api *API) vizqlRequest(url string, request vizqlQueryRequest, result interface{}) error {
+	statusCode, body, err := api.makeJSONRequest(url, POST, request)
+	if err != nil {
+		return err
+	}
+	if statusCode >= 300 {
+		vizqlErr := VizQLError{}
+		if jsonErr := json.Unmarshal(body, &vizqlErr); jsonErr != nil || len(vizqlErr.ErrorCode)+len(vizqlErr.Message) == 0 {
+			return responseError(POST, url, statusCode, body)
+		}
+		vizqlErr.StatusCode = statusCode
+		return vizqlErr
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return decodeError(POST, url, body, err)
+	}
+	return nil
+}
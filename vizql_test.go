@@ -0,0 +1,197 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+func TestVizQLQueryJSON(t *testing.T) {
+	cases := []struct {
+		name  string
+		query *tableau4go.VizQLQuery
+		want  string
+	}{
+		{
+			"fields",
+			tableau4go.NewVizQLQuery().Dimension("Region").Measure("Sales", tableau4go.VIZQL_SUM).Calculation("Margin", "SUM([Profit])/SUM([Sales])"),
+			`{"fields":[{"fieldCaption":"Region"},{"fieldCaption":"Sales","function":"SUM"},{"calculation":"SUM([Profit])/SUM([Sales])","fieldAlias":"Margin"}]}`,
+		},
+		{
+			"sort priority follows the OrderBy calls",
+			tableau4go.NewVizQLQuery().Dimension("Region").Measure("Sales", tableau4go.VIZQL_SUM).Calculation("Margin", "SUM([Profit])/SUM([Sales])").
+				OrderBy("Margin", tableau4go.VIZQL_SORT_DESC).OrderBy("Region", tableau4go.VIZQL_SORT_ASC).OrderBy("Missing", tableau4go.VIZQL_SORT_ASC),
+			`{"fields":[{"fieldCaption":"Region","sortDirection":"ASC","sortPriority":2},{"fieldCaption":"Sales","function":"SUM"},` +
+				`{"calculation":"SUM([Profit])/SUM([Sales])","fieldAlias":"Margin","sortDirection":"DESC","sortPriority":1}]}`,
+		},
+		{
+			"set and match filters",
+			tableau4go.NewVizQLQuery().Dimension("Category").Where(tableau4go.SetFilter("Category", "Furniture", "Technology"), tableau4go.MatchFilter("Customer", "Co")),
+			`{"fields":[{"fieldCaption":"Category"}],"filters":[{"field":{"fieldCaption":"Category"},"filterType":"SET","values":["Furniture","Technology"]},` +
+				`{"field":{"fieldCaption":"Customer"},"filterType":"MATCH","contains":"Co"}]}`,
+		},
+		{
+			"range filters",
+			tableau4go.NewVizQLQuery().Dimension("Region").Where(tableau4go.RangeFilter("Sales", tableau4go.VIZQL_SUM, 0, 1000), tableau4go.DateRangeFilter("Order Date", "2023-01-01", "2023-12-31")),
+			`{"fields":[{"fieldCaption":"Region"}],"filters":[{"field":{"fieldCaption":"Sales","function":"SUM"},"filterType":"QUANTITATIVE_NUMERICAL","quantitativeFilterType":"RANGE","min":0,"max":1000},` +
+				`{"field":{"fieldCaption":"Order Date"},"filterType":"QUANTITATIVE_DATE","quantitativeFilterType":"RANGE","minDate":"2023-01-01","maxDate":"2023-12-31"}]}`,
+		},
+		{
+			"top filter",
+			tableau4go.NewVizQLQuery().Dimension("Customer").Where(tableau4go.TopFilter("Customer", 10, "Sales", tableau4go.VIZQL_SUM, "TOP")),
+			`{"fields":[{"fieldCaption":"Customer"}],"filters":[{"field":{"fieldCaption":"Customer"},"filterType":"TOP","howMany":10,"fieldToMeasure":{"fieldCaption":"Sales","function":"SUM"},"direction":"TOP"}]}`,
+		},
+	}
+	for _, c := range cases {
+		got, err := json.Marshal(c.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, got, c.want)
+		}
+	}
+}
+
+// vizqlServer answers query-datasource with status and body, and hands back the
+// request body it got.
+func vizqlServer(t *testing.T, status int, body string) (*tableau4go.API, *[]byte) {
+	t.Helper()
+	var request []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/vizql-data-service/query-datasource" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		request, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return tableau4go.NewAPI(server.URL, "3.21", "", "", false), &request
+}
+
+func TestVizQLRowsScan(t *testing.T) {
+	api, request := vizqlServer(t, http.StatusOK, `{"data":[
+		{"Region":"East","SUM(Sales)":1250.5,"Orders":12},
+		{"Region":"West","SUM(Sales)":"n/a"},
+		{"Region":42}
+	]}`)
+	query := tableau4go.NewVizQLQuery().Dimension("Region").Measure("Sales", tableau4go.VIZQL_SUM)
+	rows, err := api.VizQLQueryDatasource("ds-1", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"datasource":{"datasourceLuid":"ds-1"},"query":{"fields":[{"fieldCaption":"Region"},{"fieldCaption":"Sales","function":"SUM"}]},"options":{"returnFormat":"OBJECTS"}}`
+	if string(*request) != want {
+		t.Errorf("request = %s, want %s", *request, want)
+	}
+	if rows.Len() != 3 {
+		t.Fatalf("Len() = %d", rows.Len())
+	}
+	type row struct {
+		Region string  `json:"Region"`
+		Sales  float64 `json:"SUM(Sales)"`
+		Profit float64 `json:"SUM(Profit)"`
+	}
+	var scanned row
+	if err := rows.Scan(&scanned); err == nil {
+		t.Error("Scan before Next succeeded")
+	}
+	cases := []struct {
+		name    string
+		want    row
+		wantErr bool
+	}{
+		// SUM(Profit) is not in the row and keeps its zero value
+		{"missing column", row{Region: "East", Sales: 1250.5}, false},
+		{"string for a number", row{Region: "West"}, true},
+		{"number for a string", row{}, true},
+	}
+	for _, c := range cases {
+		if !rows.Next() {
+			t.Fatalf("%s: no row", c.name)
+		}
+		scanned = row{}
+		err := rows.Scan(&scanned)
+		var typeErr *json.UnmarshalTypeError
+		if c.wantErr != errors.As(err, &typeErr) {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+		if scanned != c.want {
+			t.Errorf("%s: scanned %+v, want %+v", c.name, scanned, c.want)
+		}
+	}
+	if rows.Next() {
+		t.Error("Next past the last row")
+	}
+	if err := rows.Scan(&scanned); err == nil {
+		t.Error("Scan past the last row succeeded")
+	}
+}
+
+func TestVizQLRowsMap(t *testing.T) {
+	api, _ := vizqlServer(t, http.StatusOK, `{"data":[{"Region":"East","SUM(Sales)":1250.5}]}`)
+	rows, err := api.VizQLQueryDatasource("ds-1", tableau4go.NewVizQLQuery().Dimension("Region"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Next()
+	row, err := rows.Map()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row["Region"] != "East" || row["SUM(Sales)"] != json.Number("1250.5") {
+		t.Errorf("Map() = %#v", row)
+	}
+}
+
+func TestVizQLErrorBody(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		code     string
+		sentinel error
+	}{
+		{"vizql error", http.StatusBadRequest, `{"errorCode":"400803","message":"Unknown field: Regoin","datetime":"2024-02-01T10:00:00Z"}`, "400803", nil},
+		{"unauthorized", http.StatusUnauthorized, `{"errorCode":"401001","message":"Invalid token"}`, "401001", tableau4go.ErrUnauthorized},
+		{"not a vizql error", http.StatusBadGateway, `<html>Bad Gateway</html>`, "", nil},
+	}
+	for _, c := range cases {
+		api, _ := vizqlServer(t, c.status, c.body)
+		rows, err := api.VizQLQueryDatasource("ds-1", tableau4go.NewVizQLQuery().Dimension("Regoin"))
+		if rows != nil {
+			t.Errorf("%s: rows = %+v", c.name, rows)
+		}
+		var vizqlErr tableau4go.VizQLError
+		var apiErr *tableau4go.APIError
+		if len(c.code) > 0 {
+			if !errors.As(err, &vizqlErr) || vizqlErr.StatusCode != c.status || vizqlErr.ErrorCode != c.code || len(vizqlErr.Message) == 0 {
+				t.Errorf("%s: err = %#v", c.name, err)
+			}
+		} else if !errors.As(err, &apiErr) || apiErr.StatusCode != c.status || !strings.Contains(apiErr.Body, "Bad Gateway") {
+			t.Errorf("%s: err = %#v, want an APIError keeping the body", c.name, err)
+		}
+		if c.sentinel != nil && !errors.Is(err, c.sentinel) {
+			t.Errorf("%s: errors.Is(err, %v) is false", c.name, c.sentinel)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// events accepted by CreateWebhook
+const (
+	WEBHOOK_DATASOURCE_REFRESH_STARTED   = "datasource-refresh-started"
+	WEBHOOK_DATASOURCE_REFRESH_SUCCEEDED = "datasource-refresh-succeeded"
+	WEBHOOK_DATASOURCE_REFRESH_FAILED    = "datasource-refresh-failed"
+	WEBHOOK_DATASOURCE_CREATED           = "datasource-created"
+	WEBHOOK_DATASOURCE_UPDATED           = "datasource-updated"
+	WEBHOOK_DATASOURCE_DELETED           = "datasource-deleted"
+	WEBHOOK_WORKBOOK_REFRESH_STARTED     = "workbook-refresh-started"
+	WEBHOOK_WORKBOOK_REFRESH_SUCCEEDED   = "workbook-refresh-succeeded"
+	WEBHOOK_WORKBOOK_REFRESH_FAILED      = "workbook-refresh-failed"
+	WEBHOOK_WORKBOOK_CREATED             = "workbook-created"
+	WEBHOOK_WORKBOOK_UPDATED             = "workbook-updated"
+	WEBHOOK_WORKBOOK_DELETED             = "workbook-deleted"
+	WEBHOOK_VIEW_DELETED                 = "view-deleted"
+	WEBHOOK_ADMIN_PROMOTED               = "admin-promoted"
+	WEBHOOK_ADMIN_DEMOTED                = "admin-demoted"
+	WEBHOOK_USER_DELETED                 = "user-deleted"
+)
+
+// event_type values found in the payloads Tableau posts to a webhook destination
+const (
+	EVENT_DATASOURCE_REFRESH_STARTED   = "DatasourceRefreshStarted"
+	EVENT_DATASOURCE_REFRESH_SUCCEEDED = "DatasourceRefreshSucceeded"
+	EVENT_DATASOURCE_REFRESH_FAILED    = "DatasourceRefreshFailed"
+	EVENT_DATASOURCE_CREATED           = "DatasourceCreated"
+	EVENT_DATASOURCE_UPDATED           = "DatasourceUpdated"
+	EVENT_DATASOURCE_DELETED           = "DatasourceDeleted"
+	EVENT_WORKBOOK_REFRESH_STARTED     = "WorkbookRefreshStarted"
+	EVENT_WORKBOOK_REFRESH_SUCCEEDED   = "WorkbookRefreshSucceeded"
+	EVENT_WORKBOOK_REFRESH_FAILED      = "WorkbookRefreshFailed"
+	EVENT_WORKBOOK_CREATED             = "WorkbookCreated"
+	EVENT_WORKBOOK_UPDATED             = "WorkbookUpdated"
+	EVENT_WORKBOOK_DELETED             = "WorkbookDeleted"
+	EVENT_VIEW_DELETED                 = "ViewDeleted"
+	EVENT_ADMIN_PROMOTED               = "AdminPromoted"
+	EVENT_ADMIN_DEMOTED                = "AdminDemoted"
+	EVENT_USER_DELETED                 = "UserDeleted"
+)
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#create_webhook
+func (api *API) CreateWebhook(siteId string, webhook Webhook) (*Webhook, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/webhooks", api.Server, api.Version, siteId)
+	createWebhookRequest := CreateWebhookRequest{Request: webhook}
+	headers := make(map[string]string)
+	payload, err := api.encode(createWebhookRequest, headers)
+	if err != nil {
+		return nil, err
+	}
+	retval := WebhookResponse{}
+	err = api.makeRequest(url, POST, payload, &retval, headers, connectTimeOut, readWriteTimeout)
+	return &retval.Webhook, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#list_webhooks_for_site
+func (api *API) QueryWebhooks(siteId string) ([]Webhook, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/webhooks", api.Server, api.Version, siteId)
+	headers := make(map[string]string)
+	retval := QueryWebhooksResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Webhooks.Webhooks, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#get_webhook
+func (api *API) QueryWebhook(siteId string, webhookId string) (Webhook, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/webhooks/%s", api.Server, api.Version, siteId, webhookId)
+	headers := make(map[string]string)
+	retval := WebhookResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.Webhook, err
+}
+
+// TestWebhook has the server send a test payload to the webhook destination.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#test_webhook
+func (api *API) TestWebhook(siteId string, webhookId string) (WebhookTestResult, error) {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/webhooks/%s/test", api.Server, api.Version, siteId, webhookId)
+	headers := make(map[string]string)
+	retval := TestWebhookResponse{}
+	err := api.makeRequest(url, GET, nil, &retval, headers, connectTimeOut, readWriteTimeout)
+	return retval.WebhookTestResult, err
+}
+
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_notifications.htm#delete_webhook
+func (api *API) DeleteWebhook(siteId string, webhookId string) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/webhooks/%s", api.Server, api.Version, siteId, webhookId)
+	return api.delete(url)
+}
+
+// WebhookEvent is the JSON body Tableau posts to a webhook destination.
+type WebhookEvent struct {
+	Resource     string    `json:"resource"`
+	EventType    string    `json:"event_type"`
+	ResourceName string    `json:"resource_name"`
+	SiteLUID     string    `json:"site_luid"`
+	ResourceLUID string    `json:"resource_luid"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func DecodeWebhookEvent(r io.Reader) (WebhookEvent, error) {
+	event := WebhookEvent{}
+	err := json.NewDecoder(r).Decode(&event)
+	return event, err
+}
+
+// webhook payloads are a handful of fields, anything bigger is not from Tableau
+const maxWebhookBodyBytes = 1 << 20
+
+// WebhookHandler is an http.Handler for a webhook destination url. Each payload
+// is decoded and passed to the function registered for its event_type, or to
+// Default when there is none. A handler error turns into a 500 so Tableau
+// records the delivery as failed.
+type WebhookHandler struct {
+	Default  func(WebhookEvent) error
+	handlers map[string]func(WebhookEvent) error
+}
+
+func NewWebhookHandler(defaultHandler func(WebhookEvent) error) *WebhookHandler {
+	return &WebhookHandler{Default: defaultHandler, handlers: make(map[string]func(WebhookEvent) error)}
+}
+
+// On registers fn for an event_type such as EVENT_DATASOURCE_REFRESH_FAILED.
+func (h *WebhookHandler) On(eventType string, fn func(WebhookEvent) error) *WebhookHandler {
+	if h.handlers == nil {
+		h.handlers = make(map[string]func(WebhookEvent) error)
+	}
+	h.handlers[eventType] = fn
+	return h
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != POST {
+		w.Header().Set("Allow", POST)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	event, err := DecodeWebhookEvent(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid webhook payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	fn, ok := h.handlers[event.EventType]
+	if !ok {
+		fn = h.Default
+	}
+	if fn != nil {
+		if err := fn(event); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
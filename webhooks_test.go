@@ -0,0 +1,124 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattbaird/tableau4go"
+)
+
+const refreshFailed = `{
+  "resource": "DATASOURCE",
+  "event_type": "DatasourceRefreshFailed",
+  "resource_name": "Sales",
+  "site_luid": "site-1",
+  "resource_luid": "ds-1",
+  "created_at": "2023-11-14T08:30:00Z"
+}`
+
+func postWebhook(handler http.Handler, method string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/tableau/webhook", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestWebhookHandlerDispatch(t *testing.T) {
+	var got []string
+	var failed tableau4go.WebhookEvent
+	handler := tableau4go.NewWebhookHandler(func(event tableau4go.WebhookEvent) error {
+		got = append(got, "default:"+event.EventType)
+		return nil
+	}).On(tableau4go.EVENT_DATASOURCE_REFRESH_FAILED, func(event tableau4go.WebhookEvent) error {
+		got = append(got, "failed")
+		failed = event
+		return nil
+	})
+	if resp := postWebhook(handler, http.MethodPost, refreshFailed); resp.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", resp.Code)
+	}
+	want := tableau4go.WebhookEvent{
+		Resource:     "DATASOURCE",
+		EventType:    tableau4go.EVENT_DATASOURCE_REFRESH_FAILED,
+		ResourceName: "Sales",
+		SiteLUID:     "site-1",
+		ResourceLUID: "ds-1",
+		CreatedAt:    time.Date(2023, 11, 14, 8, 30, 0, 0, time.UTC),
+	}
+	if failed != want {
+		t.Errorf("event = %+v, want %+v", failed, want)
+	}
+	created := strings.Replace(refreshFailed, tableau4go.EVENT_DATASOURCE_REFRESH_FAILED, tableau4go.EVENT_DATASOURCE_CREATED, 1)
+	if resp := postWebhook(handler, http.MethodPost, created); resp.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", resp.Code)
+	}
+	if len(got) != 2 || got[0] != "failed" || got[1] != "default:"+tableau4go.EVENT_DATASOURCE_CREATED {
+		t.Errorf("handlers called: %v", got)
+	}
+	// without a Default, unregistered events are acknowledged and dropped
+	if resp := postWebhook(tableau4go.NewWebhookHandler(nil), http.MethodPost, created); resp.Code != http.StatusNoContent {
+		t.Errorf("no Default: status = %d, want 204", resp.Code)
+	}
+}
+
+func TestWebhookHandlerRejects(t *testing.T) {
+	called := false
+	handler := tableau4go.NewWebhookHandler(func(tableau4go.WebhookEvent) error {
+		called = true
+		return nil
+	})
+	oversized := `{"event_type":"DatasourceCreated","resource_name":"` + strings.Repeat("a", 2<<20) + `"}`
+	cases := []struct {
+		name   string
+		method string
+		body   string
+		status int
+	}{
+		{"GET", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"PUT", http.MethodPut, refreshFailed, http.StatusMethodNotAllowed},
+		{"malformed", http.MethodPost, `{"event_type":`, http.StatusBadRequest},
+		{"not json", http.MethodPost, "event_type=DatasourceCreated", http.StatusBadRequest},
+		{"oversized", http.MethodPost, oversized, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		resp := postWebhook(handler, c.method, c.body)
+		if resp.Code != c.status {
+			t.Errorf("%s: status = %d, want %d", c.name, resp.Code, c.status)
+		}
+		if c.status == http.StatusMethodNotAllowed && resp.Header().Get("Allow") != http.MethodPost {
+			t.Errorf("%s: Allow = %q", c.name, resp.Header().Get("Allow"))
+		}
+		if c.name == "oversized" && !strings.Contains(resp.Body.String(), "too large") {
+			t.Errorf("oversized: body = %q, want the MaxBytesReader error", resp.Body.String())
+		}
+	}
+	if called {
+		t.Error("a rejected payload reached the handler")
+	}
+}
+
+func TestWebhookHandlerError(t *testing.T) {
+	handler := tableau4go.NewWebhookHandler(nil).On(tableau4go.EVENT_DATASOURCE_REFRESH_FAILED, func(tableau4go.WebhookEvent) error {
+		return errors.New("paging failed")
+	})
+	resp := postWebhook(handler, http.MethodPost, refreshFailed)
+	if resp.Code != http.StatusInternalServerError || !strings.Contains(resp.Body.String(), "paging failed") {
+		t.Errorf("status = %d, body = %q, want a 500", resp.Code, resp.Body.String())
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tableau4go
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+)
+
+type PowerPointOptions struct {
+	MaxAge  int
+	Filters ViewFilters
+}
+
+func (o PowerPointOptions) values() url.Values {
+	values := url.Values{}
+	addMaxAge(values, o.MaxAge)
+	o.Filters.addTo(values)
+	return values
+}
+
+// DownloadWorkbookPDF writes every sheet of the workbook into a single PDF.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#download_workbook_pdf
+func (api *API) DownloadWorkbookPDF(siteId, workbookId string, options PDFOptions, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/pdf", api.Server, api.Version, siteId, workbookId)
+	return api.download(withQuery(url, options.values()), w, make(map[string]string))
+}
+
+// DownloadWorkbookPowerPoint writes the workbook as a .pptx deck with one slide per sheet.
+// https://help.tableau.com/current/api/rest_api/en-us/REST/rest_api_ref_workbooks_and_views.htm#download_workbook_powerpoint
+func (api *API) DownloadWorkbookPowerPoint(siteId, workbookId string, options PowerPointOptions, w io.Writer) error {
+	url := fmt.Sprintf("%s/api/%s/sites/%s/workbooks/%s/powerpoint", api.Server, api.Version, siteId, workbookId)
+	return api.download(withQuery(url, options.values()), w, make(map[string]string))
+}